@@ -0,0 +1,148 @@
+// Package bundle packs every remote manifest and chart an install needs into a
+// single archive, so that install can run without reaching the internet.
+package bundle
+
+import (
+	"archive/tar"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/lock"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// DefaultFileName is the archive written by bundle create when no output is given
+const DefaultFileName = "austinhome-bundle.tar.gz"
+
+const (
+	indexName    = "index.json"
+	indexVersion = 1
+)
+
+// Index lists the contents of a bundle
+type Index struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Files     []File    `json:"files"`
+	Charts    []Chart   `json:"charts"`
+}
+
+// File is a manifest or values file and the URL it was downloaded from
+type File struct {
+	URL    string `json:"url"`
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Chart is a chart archive and the repository version it was pulled from
+type Chart struct {
+	Name    string `json:"name"`
+	RepoURL string `json:"repoURL"`
+	Version string `json:"version"`
+	Path    string `json:"path"`
+	SHA256  string `json:"sha256"`
+}
+
+// Create downloads every manifest and chart referenced by cfg, verifies them
+// against the lock file and packs them into a gzipped tar archive at dest
+func Create(cfg *config.Config, l *lock.Lock, dest string) (*Index, error) {
+	out, err := os.Create(dest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", dest, err)
+	}
+	defer out.Close()
+
+	index, err := write(cfg, l, out)
+	if err == nil {
+		err = out.Close()
+	}
+	if err != nil {
+		// Never leave a truncated bundle behind
+		os.Remove(dest)
+		return nil, err
+	}
+	return index, nil
+}
+
+func write(cfg *config.Config, l *lock.Lock, out io.Writer) (*Index, error) {
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	index := &Index{Version: indexVersion, CreatedAt: time.Now().UTC()}
+
+	for _, url := range cfg.URLs() {
+		fmt.Printf("📥 Downloading %s\n", url)
+		data, err := lock.Fetch(url)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.VerifyFile(url, data); err != nil {
+			return nil, err
+		}
+
+		digest := lock.Digest(data)
+		file := File{URL: url, Path: path.Join("files", digest[:16]+"-"+path.Base(url)), SHA256: digest}
+		if err := writeEntry(tw, file.Path, data); err != nil {
+			return nil, err
+		}
+		index.Files = append(index.Files, file)
+	}
+
+	for _, ref := range cfg.Charts() {
+		fmt.Printf("📥 Downloading chart %s from %s\n", ref, ref.RepoURL)
+		data, err := lock.FetchChart(ref)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.VerifyChart(ref, data); err != nil {
+			return nil, err
+		}
+
+		chart := Chart{
+			Name:    ref.Name,
+			RepoURL: ref.RepoURL,
+			Version: ref.Version,
+			Path:    path.Join("charts", ref.String()+".tgz"),
+			SHA256:  lock.Digest(data),
+		}
+		if err := writeEntry(tw, chart.Path, data); err != nil {
+			return nil, err
+		}
+		index.Charts = append(index.Charts, chart)
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, indexName, data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %v", err)
+	}
+	return index, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %v", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package bundle
+
+import (
+	"archive/tar"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/lock"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bundle is an archive extracted to a local directory
+type Bundle struct {
+	Dir   string
+	Index Index
+
+	files  map[string]string
+	charts map[config.ChartRef]string
+}
+
+// Open extracts the bundle at archive into a temporary directory and checks
+// every file against the checksums in its index. Call Close to remove it.
+func Open(archive string) (*Bundle, error) {
+	in, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %v", err)
+	}
+	defer in.Close()
+
+	dir, err := os.MkdirTemp("", "austinhome-bundle-")
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{Dir: dir, files: map[string]string{}, charts: map[config.ChartRef]string{}}
+	if err := b.extract(in); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("failed to read bundle %s: %v", archive, err)
+	}
+	if err := b.load(); err != nil {
+		b.Close()
+		return nil, fmt.Errorf("invalid bundle %s: %v", archive, err)
+	}
+	return b, nil
+}
+
+// Close removes the extracted files
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.Dir)
+}
+
+// File returns the local copy of url
+func (b *Bundle) File(url string) (string, bool) {
+	local, ok := b.files[url]
+	return local, ok
+}
+
+// Chart returns the local archive of ref
+func (b *Bundle) Chart(ref config.ChartRef) (string, bool) {
+	local, ok := b.charts[ref]
+	return local, ok
+}
+
+// Covers fails unless the bundle holds every manifest and chart referenced by cfg
+func (b *Bundle) Covers(cfg *config.Config) error {
+	var missing []string
+	for _, url := range cfg.URLs() {
+		if _, ok := b.File(url); !ok {
+			missing = append(missing, url)
+		}
+	}
+	for _, ref := range cfg.Charts() {
+		if _, ok := b.Chart(ref); !ok {
+			missing = append(missing, fmt.Sprintf("chart %s from %s", ref, ref.RepoURL))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("bundle does not match the configuration, missing:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+	return nil
+}
+
+func (b *Bundle) extract(r io.Reader) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target, err := b.localPath(header.Name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		}
+
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (b *Bundle) load() error {
+	data, err := os.ReadFile(filepath.Join(b.Dir, indexName))
+	if err != nil {
+		return fmt.Errorf("missing %s", indexName)
+	}
+	if err := json.Unmarshal(data, &b.Index); err != nil {
+		return fmt.Errorf("failed to parse %s: %v", indexName, err)
+	}
+	if b.Index.Version != indexVersion {
+		return fmt.Errorf("unsupported bundle version %d", b.Index.Version)
+	}
+
+	for _, file := range b.Index.Files {
+		local, err := b.verified(file.Path, file.SHA256)
+		if err != nil {
+			return err
+		}
+		b.files[file.URL] = local
+	}
+
+	for _, chart := range b.Index.Charts {
+		local, err := b.verified(chart.Path, chart.SHA256)
+		if err != nil {
+			return err
+		}
+		b.charts[config.ChartRef{Name: chart.Name, RepoURL: chart.RepoURL, Version: chart.Version}] = local
+	}
+	return nil
+}
+
+// verified returns the local path of an archive entry after checking its checksum
+func (b *Bundle) verified(name, digest string) (string, error) {
+	local, err := b.localPath(name)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(local)
+	if err != nil {
+		return "", fmt.Errorf("missing %s", name)
+	}
+
+	if lock.Digest(data) != digest {
+		return "", fmt.Errorf("checksum mismatch for %s", name)
+	}
+	return local, nil
+}
+
+// localPath maps an archive entry name into Dir, rejecting names that escape it
+func (b *Bundle) localPath(name string) (string, error) {
+	target := filepath.Join(b.Dir, filepath.FromSlash(name))
+	if !strings.HasPrefix(target, b.Dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal path %q in bundle", name)
+	}
+	return target, nil
+}
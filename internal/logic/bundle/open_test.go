@@ -0,0 +1,196 @@
+package bundle
+
+import (
+	"archive/tar"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/lock"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const manifestURL = "https://example.com/manifests/issuer.yaml"
+
+var chartRef = config.ChartRef{Name: "metallb", RepoURL: "https://metallb.github.io/metallb", Version: "0.15.2"}
+
+type entry struct {
+	name string
+	data []byte
+}
+
+// archive writes entries to a bundle in dir the way Create lays them out
+func archive(t *testing.T, dir string, entries []entry) string {
+	t.Helper()
+	path := filepath.Join(dir, DefaultFileName)
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		if err := writeEntry(tw, e.name, e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// contents returns the entries of a valid bundle holding one manifest and one
+// chart, after edit had the chance to change the index
+func contents(t *testing.T, edit func(*Index)) []entry {
+	t.Helper()
+	manifest, chart := []byte("kind: ClusterIssuer\n"), []byte("chart archive")
+	index := Index{
+		Version: indexVersion,
+		Files:   []File{{URL: manifestURL, Path: "files/issuer.yaml", SHA256: lock.Digest(manifest)}},
+		Charts: []Chart{{
+			Name: chartRef.Name, RepoURL: chartRef.RepoURL, Version: chartRef.Version,
+			Path: "charts/metallb-0.15.2.tgz", SHA256: lock.Digest(chart),
+		}},
+	}
+	if edit != nil {
+		edit(&index)
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []entry{{"files/issuer.yaml", manifest}, {"charts/metallb-0.15.2.tgz", chart}, {indexName, data}}
+}
+
+func TestOpen(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	b, err := Open(archive(t, t.TempDir(), contents(t, nil)))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	local, ok := b.File(manifestURL)
+	if !ok {
+		t.Fatalf("bundle has no copy of %s", manifestURL)
+	}
+	if data, err := os.ReadFile(local); err != nil || string(data) != "kind: ClusterIssuer\n" {
+		t.Fatalf("local copy holds %q (%v)", data, err)
+	}
+	if _, ok := b.Chart(chartRef); !ok {
+		t.Fatalf("bundle has no chart %s", chartRef)
+	}
+	if _, ok := b.Chart(config.ChartRef{Name: chartRef.Name, RepoURL: chartRef.RepoURL, Version: "0.15.3"}); ok {
+		t.Fatal("bundle returned a chart for another version")
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(b.Dir); !os.IsNotExist(err) {
+		t.Fatalf("Close left %s behind (%v)", b.Dir, err)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries func(t *testing.T) []entry
+		wantErr string
+	}{
+		{
+			name: "entry escaping the directory",
+			entries: func(t *testing.T) []entry {
+				return append([]entry{{"../escaped.yaml", []byte("kind: Secret\n")}}, contents(t, nil)...)
+			},
+			wantErr: `illegal path "../escaped.yaml"`,
+		},
+		{
+			name: "nested entry escaping the directory",
+			entries: func(t *testing.T) []entry {
+				return append([]entry{{"files/../../escaped.yaml", []byte("kind: Secret\n")}}, contents(t, nil)...)
+			},
+			wantErr: "illegal path",
+		},
+		{
+			name: "index path escaping the directory",
+			entries: func(t *testing.T) []entry {
+				return contents(t, func(index *Index) { index.Files[0].Path = "../files/issuer.yaml" })
+			},
+			wantErr: "illegal path",
+		},
+		{
+			name: "tampered file",
+			entries: func(t *testing.T) []entry {
+				entries := contents(t, nil)
+				entries[0].data = []byte("kind: ClusterIssuer\nspec: {}\n")
+				return entries
+			},
+			wantErr: "checksum mismatch for files/issuer.yaml",
+		},
+		{
+			name: "tampered chart",
+			entries: func(t *testing.T) []entry {
+				entries := contents(t, nil)
+				entries[1].data = []byte("another chart")
+				return entries
+			},
+			wantErr: "checksum mismatch for charts/metallb-0.15.2.tgz",
+		},
+		{
+			name: "file missing from the archive",
+			entries: func(t *testing.T) []entry {
+				return contents(t, nil)[1:]
+			},
+			wantErr: "missing files/issuer.yaml",
+		},
+		{
+			name: "no index",
+			entries: func(t *testing.T) []entry {
+				return contents(t, nil)[:2]
+			},
+			wantErr: "missing " + indexName,
+		},
+		{
+			name: "unsupported version",
+			entries: func(t *testing.T) []entry {
+				return contents(t, func(index *Index) { index.Version = indexVersion + 1 })
+			},
+			wantErr: "unsupported bundle version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Extract below tmp so that an escaping entry would land in tmp itself
+			tmp := t.TempDir()
+			t.Setenv("TMPDIR", tmp)
+			path := archive(t, t.TempDir(), tt.entries(t))
+
+			b, err := Open(path)
+			if err == nil {
+				b.Close()
+				t.Fatal("Open accepted the bundle")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+			}
+
+			// Nothing may be written outside the extraction directory, which is removed again
+			left, err := os.ReadDir(tmp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(left) != 0 {
+				t.Fatalf("Open left %d entries in %s, first %s", len(left), tmp, left[0].Name())
+			}
+		})
+	}
+}
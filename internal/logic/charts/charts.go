@@ -0,0 +1,233 @@
+// Package charts installs Helm charts in-process through the Helm SDK, so no
+// helm binary is needed and the user's helm repositories are left untouched.
+package charts
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+const installTimeout = 10 * time.Minute
+
+// Release describes a chart release to install or upgrade
+type Release struct {
+	Name      string
+	Namespace string
+	// Chart is the chart name within RepoURL
+	Chart   string
+	RepoURL string
+	Version string
+	// ChartPath is a local chart archive used instead of downloading Chart from RepoURL
+	ChartPath string
+	// ValuesURLs are values files applied in order before Set
+	ValuesURLs []string
+	// Set holds key=value overrides, like helm --set
+	Set []string
+}
+
+// Info is the deployed state of a release
+type Info struct {
+	Chart   string
+	Version string
+	Status  string
+}
+
+// settings returns Helm settings bound to the kube client's kubeconfig and
+// context, with repository state kept under ~/.austinhome/helm
+func settings() (*cli.EnvSettings, error) {
+	appDir, err := common.AppDir()
+	if err != nil {
+		return nil, err
+	}
+
+	s := cli.New()
+	s.KubeConfig, s.KubeContext = kube.Kubeconfig()
+	s.RepositoryConfig = filepath.Join(appDir, "helm", "repositories.yaml")
+	s.RepositoryCache = filepath.Join(appDir, "helm", "cache")
+	s.RegistryConfig = filepath.Join(appDir, "helm", "registry.json")
+	return s, nil
+}
+
+// configure builds the Helm configuration of the actions in a namespace
+// instead of actionConfig when set
+var configure func(namespace string) (*action.Configuration, error)
+
+// SetConfiguration makes every action use the Helm configuration built by fn,
+// e.g. one on in-memory storage with a fake kube client. nil restores the
+// configuration of the kube client's cluster.
+func SetConfiguration(fn func(namespace string) (*action.Configuration, error)) {
+	configure = fn
+}
+
+func actionConfig(s *cli.EnvSettings, namespace string) (*action.Configuration, error) {
+	if configure != nil {
+		return configure(namespace)
+	}
+
+	cfg := new(action.Configuration)
+	quiet := func(string, ...interface{}) {}
+	if err := cfg.Init(s.RESTClientGetter(), namespace, "secret", quiet); err != nil {
+		return nil, fmt.Errorf("failed to initialize Helm for namespace %s: %w", namespace, err)
+	}
+	return cfg, nil
+}
+
+// Install installs the release, or upgrades it when it already exists
+func Install(ctx context.Context, r Release) error {
+	s, err := settings()
+	if err != nil {
+		return err
+	}
+	cfg, err := actionConfig(s, r.Namespace)
+	if err != nil {
+		return err
+	}
+
+	last, err := cfg.Releases.Last(r.Name)
+	exists := err == nil
+	if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return fmt.Errorf("failed to read history of release %s: %w", r.Name, err)
+	}
+	if exists && last.Info.Status.IsPending() {
+		if exists, err = recoverPending(cfg, last); err != nil {
+			return err
+		}
+	}
+
+	chartPath := r.ChartPath
+	if chartPath == "" {
+		if chartPath, err = Pull(config.ChartRef{Name: r.Chart, RepoURL: r.RepoURL, Version: r.Version}); err != nil {
+			return err
+		}
+	}
+
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return fmt.Errorf("failed to load chart %s: %w", r.Chart, err)
+	}
+
+	valueOptions := values.Options{ValueFiles: r.ValuesURLs, Values: r.Set}
+	vals, err := valueOptions.MergeValues(getter.All(s))
+	if err != nil {
+		return fmt.Errorf("failed to build values for %s: %w", r.Name, err)
+	}
+
+	if exists {
+		upgrade := action.NewUpgrade(cfg)
+		upgrade.Namespace = r.Namespace
+		upgrade.Timeout = installTimeout
+		if _, err := upgrade.RunWithContext(ctx, r.Name, chart, vals); err != nil {
+			return fmt.Errorf("failed to upgrade release %s: %w", r.Name, err)
+		}
+		return nil
+	}
+
+	install := action.NewInstall(cfg)
+	install.ReleaseName = r.Name
+	install.Namespace = r.Namespace
+	install.CreateNamespace = true
+	install.Timeout = installTimeout
+	if _, err := install.RunWithContext(ctx, chart, vals); err != nil {
+		return fmt.Errorf("failed to install release %s: %w", r.Name, err)
+	}
+	return nil
+}
+
+// recoverPending clears a release left pending by an interrupted run, which
+// would otherwise block every later upgrade. A release that was never deployed
+// is removed so it can be installed again, any other is rolled back. It
+// reports whether the release still exists.
+func recoverPending(cfg *action.Configuration, last *release.Release) (bool, error) {
+	fmt.Printf("⚠️ Release %s was left %s by an interrupted run, recovering...\n", last.Name, last.Info.Status)
+
+	if last.Version == 1 {
+		uninstall := action.NewUninstall(cfg)
+		uninstall.Timeout = installTimeout
+		if _, err := uninstall.Run(last.Name); err != nil {
+			return true, fmt.Errorf("failed to remove pending release %s: %w", last.Name, err)
+		}
+		return false, nil
+	}
+
+	rollback := action.NewRollback(cfg)
+	rollback.Timeout = installTimeout
+	if err := rollback.Run(last.Name); err != nil {
+		return true, fmt.Errorf("failed to roll back pending release %s: %w", last.Name, err)
+	}
+	return true, nil
+}
+
+// Uninstall removes the release, succeeding if it does not exist
+func Uninstall(name, namespace string) error {
+	s, err := settings()
+	if err != nil {
+		return err
+	}
+	cfg, err := actionConfig(s, namespace)
+	if err != nil {
+		return err
+	}
+
+	uninstall := action.NewUninstall(cfg)
+	uninstall.IgnoreNotFound = true
+	if _, err := uninstall.Run(name); err != nil {
+		return fmt.Errorf("failed to uninstall release %s: %w", name, err)
+	}
+	return nil
+}
+
+// Status returns the deployed chart and status of a release
+func Status(name, namespace string) (*Info, error) {
+	s, err := settings()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := actionConfig(s, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	release, err := action.NewStatus(cfg).Run(name)
+	if errors.Is(err, driver.ErrReleaseNotFound) {
+		return nil, &kube.NotFoundError{Kind: "helm release", Namespace: namespace, Name: name}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status of release %s: %w", name, err)
+	}
+
+	info := &Info{Status: release.Info.Status.String()}
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		info.Chart = release.Chart.Metadata.Name
+		info.Version = release.Chart.Metadata.Version
+	}
+	return info, nil
+}
+
+// Pull downloads a chart archive into the Helm cache and returns its path
+func Pull(ref config.ChartRef) (string, error) {
+	s, err := settings()
+	if err != nil {
+		return "", err
+	}
+
+	pathOptions := action.ChartPathOptions{RepoURL: ref.RepoURL, Version: ref.Version}
+	chartPath, err := pathOptions.LocateChart(ref.Name, s)
+	if err != nil {
+		return "", fmt.Errorf("failed to download chart %s from %s: %w", ref, ref.RepoURL, err)
+	}
+	return chartPath, nil
+}
@@ -0,0 +1,191 @@
+package cluster
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/manifest"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// colima runs K3s inside a Colima VM on macOS
+type colima struct {
+	cfg *config.Config
+}
+
+func (c *colima) Name() string {
+	return config.ProviderColima
+}
+
+func (c *colima) Prerequisites() error {
+	if !common.IsCommandAvailable("brew") {
+		return fmt.Errorf("Homebrew is required but not installed. Visit https://brew.sh/ to install it")
+	}
+	return nil
+}
+
+func (c *colima) InstallTooling() error {
+	if !common.IsCommandAvailable("colima") {
+		fmt.Println("🔧 Installing Colima...")
+		if err := brewInstall("colima"); err != nil {
+			return fmt.Errorf("failed to install Colima: %v", err)
+		}
+	} else {
+		fmt.Println("✅ Colima is already installed")
+	}
+	return nil
+}
+
+func (c *colima) Create() error {
+	fmt.Println("⚙️ Setting up Colima K3s cluster...")
+
+	if err := c.stopExisting(); err != nil {
+		return fmt.Errorf("failed to stop existing Colima: %v", err)
+	}
+
+	// Colima keeps each profile in ~/.colima/<profile>, only ours to remove if it
+	// is new. The rest of ~/.colima may belong to the user's other profiles.
+	dir, err := colimaDir()
+	if err != nil {
+		return err
+	}
+	dir = filepath.Join(dir, c.cfg.Cluster.Name)
+	_, statErr := os.Stat(dir)
+
+	if err := c.start(); err != nil {
+		return fmt.Errorf("failed to start Colima with K3s: %v", err)
+	}
+
+	if errors.Is(statErr, os.ErrNotExist) {
+		if err := manifest.AddDirectory(dir); err != nil {
+			common.Warnf("failed to record %s in the install manifest: %v", dir, err)
+		}
+	}
+
+	if err := waitForNodes(); err != nil {
+		return fmt.Errorf("K3s cluster not ready: %v", err)
+	}
+
+	return nil
+}
+
+func (c *colima) stopExisting() error {
+	if c.cfg.Cluster.Provider != config.ProviderColima {
+		return fmt.Errorf("refusing to stop Colima for a %s cluster", c.cfg.Cluster.Provider)
+	}
+
+	fmt.Println("🛑 Stopping existing Colima instances if any...")
+
+	// Check if Colima is running
+	colimaName := c.cfg.Cluster.Name
+	if err := common.RunCommand("colima", "status", colimaName); err == nil {
+		fmt.Printf("🗑️ Stopping existing Colima instance: %s\n", colimaName)
+		if err := common.RunCommand("colima", "stop", colimaName); err != nil {
+			common.Warnf("failed to stop Colima: %v", err)
+		}
+
+		// Delete the instance
+		fmt.Printf("🗑️ Deleting existing Colima instance: %s\n", colimaName)
+		if err := common.RunCommand("colima", "delete", colimaName, "--force"); err != nil {
+			common.Warnf("failed to delete Colima: %v", err)
+		}
+	} else {
+		fmt.Println("ℹ️ No existing Colima instance found")
+	}
+
+	return nil
+}
+
+func (c *colima) start() error {
+	fmt.Println("🚀 Starting Colima with Kubernetes (K3s) enabled...")
+
+	// Start Colima with containerd runtime and bridged network mode
+	err := common.RunCommand("colima", "start", c.cfg.Cluster.Name,
+		"--cpu", strconv.Itoa(c.cfg.Cluster.CPUs),
+		"--memory", strconv.Itoa(c.cfg.Cluster.Memory),
+		"--runtime", "containerd",
+		"--network-address",
+		"--network-mode", "bridged",
+		"--network-interface", c.cfg.Network.Interface,
+		"--kubernetes")
+
+	if err != nil {
+		return fmt.Errorf("failed to start Colima with K3s: %v", err)
+	}
+
+	fmt.Println("✅ Colima with K3s started successfully")
+	return nil
+}
+
+func (c *colima) Delete() error {
+	fmt.Println("⏹️ Stopping Colima instance...")
+	if err := common.RunCommand("colima", "stop", c.cfg.Cluster.Name); err != nil {
+		common.Warnf("failed to stop Colima: %v", err)
+	}
+
+	fmt.Println("💥 Deleting Colima instance...")
+	if err := common.RunCommand("colima", "delete", c.cfg.Cluster.Name, "--force"); err != nil {
+		common.Warnf("failed to delete Colima: %v", err)
+	}
+	return nil
+}
+
+func (c *colima) Address() (string, error) {
+	fmt.Println("🔍 Getting Colima VM IP address...")
+
+	// Get Colima VM IP
+	output, err := common.RunCommandOutput("colima", "list", "--format", "{{.IPAddress}}")
+	if err != nil {
+		return "", fmt.Errorf("failed to get Colima IP: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "IPAddress" {
+			fmt.Printf("✅ Found Colima IP: %s\n", line)
+			return line, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not find Colima VM IP address")
+}
+
+// Kubeconfig returns the context Colima writes into ~/.kube/config for the profile
+func (c *colima) Kubeconfig() (string, string) {
+	if c.cfg.Cluster.Name == "default" {
+		return "", "colima"
+	}
+	return "", "colima-" + c.cfg.Cluster.Name
+}
+
+func (c *colima) Managed() bool {
+	return true
+}
+
+// DataDirs returns the profile directory and the Lima VM and disk behind it
+func (c *colima) DataDirs() []string {
+	dir, err := colimaDir()
+	if err != nil {
+		return nil
+	}
+	_, context := c.Kubeconfig()
+	return []string{
+		filepath.Join(dir, c.cfg.Cluster.Name),
+		filepath.Join(dir, "_lima", context),
+		filepath.Join(dir, "_lima", "_disks", c.cfg.Cluster.Name),
+	}
+}
+
+// colimaDir is where Colima stores its instances
+func colimaDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(homeDir, ".colima"), nil
+}
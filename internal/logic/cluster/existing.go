@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"fmt"
+)
+
+// existing installs onto a cluster austinhome does not manage, reached through a kubeconfig context
+type existing struct {
+	cfg *config.Config
+}
+
+func (e *existing) Name() string {
+	return config.ProviderExisting
+}
+
+func (e *existing) Prerequisites() error {
+	return requireCommands("kubectl")
+}
+
+func (e *existing) InstallTooling() error {
+	return nil
+}
+
+// Create only checks that the cluster is reachable, it never modifies it
+func (e *existing) Create() error {
+	fmt.Println("🔗 Using existing cluster from kubeconfig...")
+	if err := common.RunCommand("kubectl", "cluster-info"); err != nil {
+		return fmt.Errorf("cannot reach the existing cluster: %v", err)
+	}
+	return waitForNodes()
+}
+
+func (e *existing) Delete() error {
+	return fmt.Errorf("the cluster behind context %q is not managed by austinhome, refusing to delete it", e.contextName())
+}
+
+func (e *existing) Address() (string, error) {
+	return nodeAddress()
+}
+
+func (e *existing) Kubeconfig() (string, string) {
+	return e.cfg.Cluster.Kubeconfig, e.cfg.Cluster.Context
+}
+
+func (e *existing) Managed() bool {
+	return false
+}
+
+func (e *existing) DataDirs() []string {
+	return nil
+}
+
+func (e *existing) contextName() string {
+	if e.cfg.Cluster.Context == "" {
+		return "current-context"
+	}
+	return e.cfg.Cluster.Context
+}
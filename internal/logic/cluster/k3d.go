@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"fmt"
+)
+
+// k3d runs K3s in Docker containers
+type k3d struct {
+	cfg *config.Config
+}
+
+func (k *k3d) Name() string {
+	return config.ProviderK3d
+}
+
+func (k *k3d) Prerequisites() error {
+	return requireCommands("docker")
+}
+
+func (k *k3d) InstallTooling() error {
+	return installWithBrew("k3d", "k3d", "https://k3d.io/#installation")
+}
+
+func (k *k3d) Create() error {
+	fmt.Printf("⚙️ Setting up k3d cluster %s...\n", k.cfg.Cluster.Name)
+
+	// Recreate the cluster from scratch, like the Colima provider does
+	if err := common.RunCommand("k3d", "cluster", "list", k.cfg.Cluster.Name); err == nil {
+		fmt.Printf("🗑️ Deleting existing k3d cluster: %s\n", k.cfg.Cluster.Name)
+		if err := common.RunCommand("k3d", "cluster", "delete", k.cfg.Cluster.Name); err != nil {
+			common.Warnf("failed to delete k3d cluster: %v", err)
+		}
+	}
+
+	if err := common.RunCommand("k3d", "cluster", "create", k.cfg.Cluster.Name, "--wait", "--timeout", readyTimeout.String()); err != nil {
+		return fmt.Errorf("failed to create k3d cluster: %v", err)
+	}
+
+	return waitForNodes()
+}
+
+func (k *k3d) Delete() error {
+	fmt.Printf("💥 Deleting k3d cluster %s...\n", k.cfg.Cluster.Name)
+	return common.RunCommand("k3d", "cluster", "delete", k.cfg.Cluster.Name)
+}
+
+func (k *k3d) Address() (string, error) {
+	return nodeAddress()
+}
+
+func (k *k3d) Kubeconfig() (string, string) {
+	return "", "k3d-" + k.cfg.Cluster.Name
+}
+
+func (k *k3d) Managed() bool {
+	return true
+}
+
+// DataDirs is nil since the nodes keep their data in Docker
+func (k *k3d) DataDirs() []string {
+	return nil
+}
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"fmt"
+	"os"
+	"runtime"
+)
+
+const (
+	k3sUninstallScript = "/usr/local/bin/k3s-uninstall.sh"
+	k3sKubeconfig      = "/etc/rancher/k3s/k3s.yaml"
+	k3sDataDir         = "/var/lib/rancher/k3s"
+)
+
+// k3s installs K3s natively on a Linux host
+type k3s struct {
+	cfg *config.Config
+}
+
+func (k *k3s) Name() string {
+	return config.ProviderK3s
+}
+
+func (k *k3s) Prerequisites() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("the k3s provider only runs on Linux, use colima, k3d or kind on %s", runtime.GOOS)
+	}
+	return requireCommands("curl", "sudo")
+}
+
+// InstallTooling does nothing, the K3s install script ships its own binaries
+func (k *k3s) InstallTooling() error {
+	return nil
+}
+
+func (k *k3s) Create() error {
+	fmt.Println("⚙️ Setting up native K3s cluster...")
+
+	if _, err := os.Stat(k3sUninstallScript); err == nil {
+		fmt.Println("🗑️ Removing existing K3s installation...")
+		if err := common.RunCommand("sudo", k3sUninstallScript); err != nil {
+			common.Warnf("failed to remove existing K3s: %v", err)
+		}
+	}
+
+	fmt.Printf("🚀 Installing K3s %s...\n", k.cfg.Cluster.K3sVersion)
+	script, err := verifiedArtifact(k.cfg.Cluster.K3sInstallScriptURL())
+	if err != nil {
+		return err
+	}
+	if err := common.RunCommand("sudo", "env", "INSTALL_K3S_VERSION=v"+k.cfg.Cluster.K3sVersion,
+		"sh", script, "--write-kubeconfig-mode", "644"); err != nil {
+		return fmt.Errorf("failed to install K3s: %v", err)
+	}
+
+	if err := waitForNodes(); err != nil {
+		return fmt.Errorf("K3s cluster not ready: %v", err)
+	}
+	return nil
+}
+
+func (k *k3s) Delete() error {
+	fmt.Println("💥 Uninstalling K3s...")
+	if _, err := os.Stat(k3sUninstallScript); err != nil {
+		fmt.Println("ℹ️ No K3s installation found")
+		return nil
+	}
+	return common.RunCommand("sudo", k3sUninstallScript)
+}
+
+func (k *k3s) Address() (string, error) {
+	return nodeAddress()
+}
+
+func (k *k3s) Kubeconfig() (string, string) {
+	return k3sKubeconfig, "default"
+}
+
+func (k *k3s) Managed() bool {
+	return true
+}
+
+func (k *k3s) DataDirs() []string {
+	return []string{k3sDataDir}
+}
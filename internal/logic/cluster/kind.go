@@ -0,0 +1,61 @@
+package cluster
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"fmt"
+)
+
+// kind runs Kubernetes in Docker containers
+type kind struct {
+	cfg *config.Config
+}
+
+func (k *kind) Name() string {
+	return config.ProviderKind
+}
+
+func (k *kind) Prerequisites() error {
+	return requireCommands("docker")
+}
+
+func (k *kind) InstallTooling() error {
+	return installWithBrew("kind", "kind", "https://kind.sigs.k8s.io/docs/user/quick-start/#installation")
+}
+
+func (k *kind) Create() error {
+	fmt.Printf("⚙️ Setting up kind cluster %s...\n", k.cfg.Cluster.Name)
+
+	// kind delete is a no-op when the cluster does not exist
+	if err := common.RunCommand("kind", "delete", "cluster", "--name", k.cfg.Cluster.Name); err != nil {
+		common.Warnf("failed to delete existing kind cluster: %v", err)
+	}
+
+	if err := common.RunCommand("kind", "create", "cluster", "--name", k.cfg.Cluster.Name, "--wait", readyTimeout.String()); err != nil {
+		return fmt.Errorf("failed to create kind cluster: %v", err)
+	}
+
+	return waitForNodes()
+}
+
+func (k *kind) Delete() error {
+	fmt.Printf("💥 Deleting kind cluster %s...\n", k.cfg.Cluster.Name)
+	return common.RunCommand("kind", "delete", "cluster", "--name", k.cfg.Cluster.Name)
+}
+
+func (k *kind) Address() (string, error) {
+	return nodeAddress()
+}
+
+func (k *kind) Kubeconfig() (string, string) {
+	return "", "kind-" + k.cfg.Cluster.Name
+}
+
+func (k *kind) Managed() bool {
+	return true
+}
+
+// DataDirs is nil since the nodes keep their data in Docker
+func (k *kind) DataDirs() []string {
+	return nil
+}
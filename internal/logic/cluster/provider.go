@@ -0,0 +1,164 @@
+package cluster
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"austinhome/internal/logic/manifest"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const readyTimeout = 180 * time.Second
+
+// Provider creates and removes the Kubernetes cluster the components are installed on
+type Provider interface {
+	// Name identifies the provider in config and messages
+	Name() string
+	// Prerequisites checks that the host can run this provider
+	Prerequisites() error
+	// InstallTooling installs the provider CLI if it is missing
+	InstallTooling() error
+	// Create replaces any existing cluster with a fresh one and waits until it is ready
+	Create() error
+	// Delete removes the cluster
+	Delete() error
+	// Address returns the IP address of the cluster node
+	Address() (string, error)
+	// Kubeconfig returns the kubeconfig path ("" for the default) and context ("" for the current one)
+	Kubeconfig() (path, context string)
+	// Managed reports whether austinhome owns the cluster and may recreate or delete it
+	Managed() bool
+	// DataDirs returns the host directories holding the cluster's disks and
+	// state, nil when they live elsewhere such as in Docker volumes
+	DataDirs() []string
+}
+
+// New returns the provider selected by cfg.Cluster.Provider
+func New(cfg *config.Config) (Provider, error) {
+	switch cfg.Cluster.Provider {
+	case config.ProviderColima:
+		return &colima{cfg: cfg}, nil
+	case config.ProviderK3s:
+		return &k3s{cfg: cfg}, nil
+	case config.ProviderK3d:
+		return &k3d{cfg: cfg}, nil
+	case config.ProviderKind:
+		return &kind{cfg: cfg}, nil
+	case config.ProviderExisting:
+		return &existing{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown cluster provider %q", cfg.Cluster.Provider)
+	}
+}
+
+// Bind makes the kube client, Helm and every following kubectl invocation target the provider's cluster
+func Bind(p Provider) {
+	path, context := p.Kubeconfig()
+	kube.Configure(path, context)
+	if path == "" && context == "" {
+		return
+	}
+	common.SetRunner(common.NewKubeRunner(common.CurrentRunner(), path, context))
+}
+
+// artifacts resolves remote files to local copies verified against the lock file
+var artifacts func(url string) (string, error)
+
+// UseArtifacts makes providers run the verified copies returned by resolve
+// instead of piping remote scripts into a shell
+func UseArtifacts(resolve func(url string) (string, error)) {
+	artifacts = resolve
+}
+
+// verifiedArtifact returns the verified local copy of url
+func verifiedArtifact(url string) (string, error) {
+	if artifacts == nil {
+		return "", fmt.Errorf("%s has not been verified against the lock file", url)
+	}
+	return artifacts(url)
+}
+
+// waitForNodes polls the API server until kubectl can list nodes
+func waitForNodes() error {
+	fmt.Println("⏳ Waiting for the cluster to be ready...")
+
+	startTime := time.Now()
+	for time.Since(startTime) < readyTimeout {
+		// Check if kubectl can connect to the cluster
+		if err := common.RunCommand("kubectl", "get", "nodes"); err == nil {
+			fmt.Println("✅ Cluster is ready!")
+			return nil
+		}
+
+		fmt.Printf("⏳ Still waiting... (%v elapsed)\n", time.Since(startTime).Truncate(time.Second))
+		time.Sleep(10 * time.Second)
+	}
+
+	return fmt.Errorf("timeout: cluster not ready after %v", readyTimeout)
+}
+
+// nodeAddress returns the InternalIP of the first node
+func nodeAddress() (string, error) {
+	output, err := common.RunCommandOutput("kubectl", "get", "nodes",
+		"-o", `jsonpath={.items[0].status.addresses[?(@.type=="InternalIP")].address}`)
+	if err != nil {
+		return "", fmt.Errorf("failed to get node address: %v", err)
+	}
+
+	address := strings.TrimSpace(output)
+	if address == "" {
+		return "", fmt.Errorf("node has no InternalIP address")
+	}
+	return address, nil
+}
+
+// installWithBrew installs formula with Homebrew, or explains how to install it manually
+func installWithBrew(binary, formula, manualURL string) error {
+	if common.IsCommandAvailable(binary) {
+		fmt.Printf("✅ %s is already installed\n", binary)
+		return nil
+	}
+
+	if !common.IsCommandAvailable("brew") {
+		err := fmt.Errorf("%s is required but not installed, see %s", binary, manualURL)
+		if common.IsDryRun() {
+			fmt.Printf("[dry-run] Warning: %v\n", err)
+			return nil
+		}
+		return err
+	}
+
+	fmt.Printf("🔧 Installing %s...\n", binary)
+	if err := brewInstall(formula); err != nil {
+		return fmt.Errorf("failed to install %s: %v", binary, err)
+	}
+	return nil
+}
+
+// brewInstall installs formula and records it in the install manifest, so
+// uninstall only removes formulae austinhome installed itself
+func brewInstall(formula string) error {
+	if err := common.RunCommand("brew", "install", formula); err != nil {
+		return err
+	}
+	if err := manifest.AddFormula(formula); err != nil {
+		common.Warnf("failed to record %s in the install manifest: %v", formula, err)
+	}
+	return nil
+}
+
+func requireCommands(names ...string) error {
+	var missing []string
+	for _, name := range names {
+		if !common.IsCommandAvailable(name) {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required command(s) not found in PATH: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
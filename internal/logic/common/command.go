@@ -1,10 +1,14 @@
 package common
 
 import (
+	"austinhome/internal/logic/events"
+	"austinhome/internal/logic/kube"
+	"austinhome/internal/logic/redact"
 	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -37,26 +41,64 @@ func setupCommandEnvironment(cmd *exec.Cmd) {
 	cmd.Env = env
 }
 
-func RunCommand(name string, args ...string) error {
+// Runner executes external commands on behalf of the install and uninstall steps.
+type Runner interface {
+	// Run runs a command, streaming its output to the terminal
+	Run(name string, args ...string) error
+	// RunOutput runs a command and returns its stdout
+	RunOutput(name string, args ...string) (string, error)
+	// RunWithTimeout runs a command, killing it once the timeout expires
+	RunWithTimeout(timeout time.Duration, name string, args ...string) error
+	// LookPath resolves an executable in PATH
+	LookPath(name string) (string, error)
+}
+
+var runner Runner = ExecRunner{}
+
+// SetRunner replaces the runner used by the package-level command helpers
+func SetRunner(r Runner) {
+	if r == nil {
+		r = ExecRunner{}
+	}
+	runner = r
+}
+
+// CurrentRunner returns the runner used by the package-level command helpers
+func CurrentRunner() Runner {
+	return runner
+}
+
+// ExecRunner is the default Runner which spawns real processes via os/exec
+type ExecRunner struct{}
+
+func (ExecRunner) Run(name string, args ...string) error {
 	cmd := exec.Command(name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
 	// Set up environment with enhanced PATH
 	setupCommandEnvironment(cmd)
 
-	fmt.Printf("Running: %s %s\n", name, strings.Join(args, " "))
-	return cmd.Run()
+	fmt.Printf("Running: %s\n", commandLine(name, args))
+	return runRedacted(cmd)
 }
 
-// RunCommandOutput runs a command and returns its output as a string
-func RunCommandOutput(name string, args ...string) (string, error) {
+// runRedacted runs cmd with its output streamed to the terminal, masking registered secrets
+func runRedacted(cmd *exec.Cmd) error {
+	stdout, stderr := redact.NewWriter(os.Stdout), redact.NewWriter(os.Stderr)
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	err := cmd.Run()
+	stdout.Flush()
+	stderr.Flush()
+	return err
+}
+
+func (ExecRunner) RunOutput(name string, args ...string) (string, error) {
 	cmd := exec.Command(name, args...)
 
 	// Set up environment with enhanced PATH
 	setupCommandEnvironment(cmd)
 
-	fmt.Printf("Running: %s %s\n", name, strings.Join(args, " "))
+	fmt.Printf("Running: %s\n", commandLine(name, args))
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -66,25 +108,17 @@ func RunCommandOutput(name string, args ...string) (string, error) {
 	return string(output), nil
 }
 
-func IsCommandAvailable(name string) bool {
-	_, err := exec.LookPath(name)
-	return err == nil
-}
-
-// RunCommandWithTimeout runs a command with a timeout
-func RunCommandWithTimeout(timeout time.Duration, name string, args ...string) error {
+func (ExecRunner) RunWithTimeout(timeout time.Duration, name string, args ...string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
 	// Set up environment with enhanced PATH
 	setupCommandEnvironment(cmd)
 
-	fmt.Printf("Running: %s %s (timeout: %v)\n", name, strings.Join(args, " "), timeout)
-	err := cmd.Run()
+	fmt.Printf("Running: %s (timeout: %v)\n", commandLine(name, args), timeout)
+	err := runRedacted(cmd)
 
 	if ctx.Err() == context.DeadlineExceeded {
 		return fmt.Errorf("command timed out after %v", timeout)
@@ -93,6 +127,43 @@ func RunCommandWithTimeout(timeout time.Duration, name string, args ...string) e
 	return err
 }
 
+func (ExecRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+func RunCommand(name string, args ...string) error {
+	started := time.Now()
+	err := runner.Run(name, args...)
+	events.CommandExecuted(commandLine(name, args), time.Since(started), err)
+	return err
+}
+
+// RunCommandOutput runs a command and returns its output as a string
+func RunCommandOutput(name string, args ...string) (string, error) {
+	started := time.Now()
+	output, err := runner.RunOutput(name, args...)
+	events.CommandExecuted(commandLine(name, args), time.Since(started), err)
+	return output, err
+}
+
+func IsCommandAvailable(name string) bool {
+	_, err := runner.LookPath(name)
+	return err == nil
+}
+
+// RunCommandWithTimeout runs a command with a timeout
+func RunCommandWithTimeout(timeout time.Duration, name string, args ...string) error {
+	started := time.Now()
+	err := runner.RunWithTimeout(timeout, name, args...)
+	events.CommandExecuted(commandLine(name, args), time.Since(started), err)
+	return err
+}
+
+// commandLine renders a command for logs with every registered secret masked
+func commandLine(name string, args []string) string {
+	return redact.String(strings.TrimSpace(name + " " + strings.Join(args, " ")))
+}
+
 // RunMultipassCommand runs multipass with absolute path resolution
 func RunMultipassCommand(args ...string) error {
 	// Try to find multipass in common locations
@@ -151,28 +222,29 @@ func WaitForPodsReady(namespace, selector string, maxWaitTime time.Duration) err
 	fmt.Printf("⏳ Waiting for pods in namespace %s (%s) to be ready (max %v)...\n",
 		namespace, selectorText, maxWaitTime)
 
-	checkInterval := 10 * time.Second
-	startTime := time.Now()
-
-	for time.Since(startTime) < maxWaitTime {
-		var err error
-		if selector == "" {
-			// Use --all when no selector is provided
-			err = RunCommand("kubectl", "wait", "--namespace", namespace,
-				"--for=condition=ready", "pod", "--all", "--timeout=0s")
-		} else {
-			err = RunCommand("kubectl", "wait", "--namespace", namespace,
-				"--for=condition=ready", "pod", "--selector="+selector, "--timeout=0s")
-		}
+	if IsDryRun() {
+		fmt.Printf("[dry-run] Would wait for pods in namespace %s (%s)\n", namespace, selectorText)
+		return nil
+	}
 
-		if err == nil {
-			fmt.Println("✅ Pods are ready!")
-			return nil
-		}
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("⏳ Still waiting... (%v elapsed)\n", time.Since(startTime).Truncate(time.Second))
-		time.Sleep(checkInterval)
+	if err := client.WaitForPodsReady(context.Background(), namespace, selector, maxWaitTime); err != nil {
+		return err
 	}
 
-	return fmt.Errorf("timeout: pods not ready after %v", maxWaitTime)
-}
\ No newline at end of file
+	fmt.Println("✅ Pods are ready!")
+	return nil
+}
+
+// AppDir returns ~/.austinhome, where austinhome keeps its local state
+func AppDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %v", err)
+	}
+	return filepath.Join(homeDir, ".austinhome"), nil
+}
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"slices"
+	"testing"
+)
+
+// useRunner installs r as the package runner for the duration of the test
+func useRunner(t *testing.T, r Runner) {
+	t.Helper()
+	previous := CurrentRunner()
+	SetRunner(r)
+	t.Cleanup(func() { SetRunner(previous) })
+}
+
+func TestRunCommandGoesThroughCurrentRunner(t *testing.T) {
+	fake := NewFakeRunner().
+		On("colima status", FakeResponse{ExitCode: 1}).
+		On("colima list", FakeResponse{Stdout: "192.168.106.2\n"})
+	useRunner(t, fake)
+
+	if err := RunCommand("colima", "status", "default"); err == nil {
+		t.Fatal("expected the scripted exit code to fail the command")
+	}
+	output, err := RunCommandOutput("colima", "list", "--format", "{{.IPAddress}}")
+	if err != nil {
+		t.Fatalf("RunCommandOutput: %v", err)
+	}
+	if output != "192.168.106.2\n" {
+		t.Fatalf("got output %q", output)
+	}
+
+	want := []string{"colima status default", "colima list --format {{.IPAddress}}"}
+	if got := fake.CommandLines(); !slices.Equal(got, want) {
+		t.Fatalf("got commands %q, want %q", got, want)
+	}
+}
+
+func TestFakeRunnerRepeatsLastResponse(t *testing.T) {
+	fake := NewFakeRunner().On("kubectl get nodes", FakeResponse{ExitCode: 1}, FakeResponse{Stdout: "node"})
+	useRunner(t, fake)
+
+	var results []bool
+	for range 3 {
+		results = append(results, RunCommand("kubectl", "get", "nodes") == nil)
+	}
+	if want := []bool{false, true, true}; !slices.Equal(results, want) {
+		t.Fatalf("got successes %v, want %v", results, want)
+	}
+}
+
+func TestIsCommandAvailableUsesRunner(t *testing.T) {
+	useRunner(t, NewFakeRunner().Missing("k3d"))
+
+	if IsCommandAvailable("k3d") {
+		t.Error("k3d is marked missing but reported available")
+	}
+	if !IsCommandAvailable("kubectl") {
+		t.Error("kubectl is not marked missing but reported unavailable")
+	}
+}
+
+func TestKubeRunnerTargetsOnlyKubectl(t *testing.T) {
+	fake := NewFakeRunner()
+	useRunner(t, NewKubeRunner(fake, "/etc/rancher/k3s/k3s.yaml", "default"))
+
+	RunCommand("kubectl", "get", "nodes")
+	RunCommand("colima", "stop", "default")
+
+	want := []string{
+		"kubectl --kubeconfig /etc/rancher/k3s/k3s.yaml --context default get nodes",
+		"colima stop default",
+	}
+	if got := fake.CommandLines(); !slices.Equal(got, want) {
+		t.Fatalf("got commands %q, want %q", got, want)
+	}
+}
+
+func TestSetRunnerNilRestoresExecRunner(t *testing.T) {
+	useRunner(t, NewFakeRunner())
+
+	SetRunner(nil)
+	if _, ok := CurrentRunner().(ExecRunner); !ok {
+		t.Fatalf("got %T, want ExecRunner", CurrentRunner())
+	}
+}
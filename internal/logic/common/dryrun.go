@@ -0,0 +1,59 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// DryRunOutput is the placeholder stdout returned by DryRunRunner
+const DryRunOutput = "<dry-run>"
+
+// DryRunRunner prints every command it is asked to run instead of executing it.
+// Binary lookups still consult the real PATH so the plan reflects this machine.
+type DryRunRunner struct {
+	Out io.Writer
+}
+
+func NewDryRunRunner(out io.Writer) DryRunRunner {
+	return DryRunRunner{Out: out}
+}
+
+func (d DryRunRunner) Run(name string, args ...string) error {
+	d.print(name, args, "")
+	return nil
+}
+
+func (d DryRunRunner) RunOutput(name string, args ...string) (string, error) {
+	d.print(name, args, "")
+	return DryRunOutput, nil
+}
+
+func (d DryRunRunner) RunWithTimeout(timeout time.Duration, name string, args ...string) error {
+	d.print(name, args, fmt.Sprintf(" (timeout: %v)", timeout))
+	return nil
+}
+
+func (d DryRunRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+func (d DryRunRunner) print(name string, args []string, suffix string) {
+	fmt.Fprintf(d.Out, "[dry-run] Would run: %s%s\n", commandLine(name, args), suffix)
+}
+
+// IsDryRun reports whether the current runner, or a runner it wraps, only prints commands
+func IsDryRun() bool {
+	current := runner
+	for {
+		switch r := current.(type) {
+		case DryRunRunner:
+			return true
+		case interface{ Unwrap() Runner }:
+			current = r.Unwrap()
+		default:
+			return false
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Invocation records a single command executed through a FakeRunner
+type Invocation struct {
+	Name    string
+	Args    []string
+	Timeout time.Duration
+}
+
+// CommandLine returns the invocation as a single space separated string
+func (i Invocation) CommandLine() string {
+	return strings.TrimSpace(i.Name + " " + strings.Join(i.Args, " "))
+}
+
+// FakeResponse is the canned result returned for a matching command
+type FakeResponse struct {
+	Stdout   string
+	ExitCode int
+	Err      error
+}
+
+type fakeRule struct {
+	prefix    string
+	responses []FakeResponse
+	calls     int
+}
+
+// FakeRunner is a scriptable Runner which records every invocation instead of
+// spawning processes. Commands without a matching rule succeed with empty output.
+type FakeRunner struct {
+	mu          sync.Mutex
+	rules       []*fakeRule
+	missing     map[string]bool
+	invocations []Invocation
+}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{missing: map[string]bool{}}
+}
+
+// On registers responses for every command line starting with prefix.
+// Responses are returned in order and the last one repeats once exhausted.
+// Rules are matched in registration order.
+func (f *FakeRunner) On(prefix string, responses ...FakeResponse) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(responses) == 0 {
+		responses = []FakeResponse{{}}
+	}
+	f.rules = append(f.rules, &fakeRule{prefix: prefix, responses: responses})
+	return f
+}
+
+// Missing marks binaries which LookPath should report as not installed
+func (f *FakeRunner) Missing(names ...string) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, name := range names {
+		f.missing[name] = true
+	}
+	return f
+}
+
+// Invocations returns a copy of every command executed so far
+func (f *FakeRunner) Invocations() []Invocation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Invocation(nil), f.invocations...)
+}
+
+// CommandLines returns every executed command as a single string
+func (f *FakeRunner) CommandLines() []string {
+	var lines []string
+	for _, inv := range f.Invocations() {
+		lines = append(lines, inv.CommandLine())
+	}
+	return lines
+}
+
+func (f *FakeRunner) Run(name string, args ...string) error {
+	_, err := f.record(Invocation{Name: name, Args: args})
+	return err
+}
+
+func (f *FakeRunner) RunOutput(name string, args ...string) (string, error) {
+	return f.record(Invocation{Name: name, Args: args})
+}
+
+func (f *FakeRunner) RunWithTimeout(timeout time.Duration, name string, args ...string) error {
+	_, err := f.record(Invocation{Name: name, Args: args, Timeout: timeout})
+	return err
+}
+
+func (f *FakeRunner) LookPath(name string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.missing[name] {
+		return "", fmt.Errorf("executable file not found in $PATH: %s", name)
+	}
+	return "/usr/local/bin/" + name, nil
+}
+
+func (f *FakeRunner) record(inv Invocation) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.invocations = append(f.invocations, inv)
+
+	line := inv.CommandLine()
+	for _, rule := range f.rules {
+		if !strings.HasPrefix(line, rule.prefix) {
+			continue
+		}
+
+		resp := rule.responses[len(rule.responses)-1]
+		if rule.calls < len(rule.responses) {
+			resp = rule.responses[rule.calls]
+		}
+		rule.calls++
+
+		if resp.Err != nil {
+			return resp.Stdout, resp.Err
+		}
+		if resp.ExitCode != 0 {
+			return resp.Stdout, fmt.Errorf("exit status %d", resp.ExitCode)
+		}
+		return resp.Stdout, nil
+	}
+
+	return "", nil
+}
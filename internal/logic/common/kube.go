@@ -0,0 +1,52 @@
+package common
+
+import "time"
+
+// KubeRunner wraps a Runner and points every kubectl invocation at a specific
+// kubeconfig file and context
+type KubeRunner struct {
+	Runner     Runner
+	Kubeconfig string
+	Context    string
+}
+
+func NewKubeRunner(inner Runner, kubeconfig, context string) KubeRunner {
+	return KubeRunner{Runner: inner, Kubeconfig: kubeconfig, Context: context}
+}
+
+// Unwrap returns the runner commands are delegated to
+func (k KubeRunner) Unwrap() Runner {
+	return k.Runner
+}
+
+func (k KubeRunner) Run(name string, args ...string) error {
+	return k.Runner.Run(name, k.args(name, args)...)
+}
+
+func (k KubeRunner) RunOutput(name string, args ...string) (string, error) {
+	return k.Runner.RunOutput(name, k.args(name, args)...)
+}
+
+func (k KubeRunner) RunWithTimeout(timeout time.Duration, name string, args ...string) error {
+	return k.Runner.RunWithTimeout(timeout, name, k.args(name, args)...)
+}
+
+func (k KubeRunner) LookPath(name string) (string, error) {
+	return k.Runner.LookPath(name)
+}
+
+// args prepends the global kubeconfig and context flags understood by kubectl
+func (k KubeRunner) args(name string, args []string) []string {
+	if name != "kubectl" {
+		return args
+	}
+
+	var global []string
+	if k.Kubeconfig != "" {
+		global = append(global, "--kubeconfig", k.Kubeconfig)
+	}
+	if k.Context != "" {
+		global = append(global, "--context", k.Context)
+	}
+	return append(global, args...)
+}
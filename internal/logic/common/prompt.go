@@ -0,0 +1,44 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdinReader is shared by every prompt so buffered piped input is not lost between them
+var stdinReader = bufio.NewReader(os.Stdin)
+
+// StdinIsTerminal reports whether the user can be prompted interactively
+func StdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// ReadLine reads one line from stdin without the trailing newline. A final
+// line without newline is returned as well.
+func ReadLine() (string, error) {
+	input, err := stdinReader.ReadString('\n')
+	if err != nil && input == "" {
+		return "", err
+	}
+	return strings.TrimRight(input, "\r\n"), nil
+}
+
+// Confirm asks a yes/no question, defaulting to no
+func Confirm(question string) bool {
+	fmt.Printf("%s [y/N]: ", question)
+
+	input, err := ReadLine()
+	if err != nil {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
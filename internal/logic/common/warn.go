@@ -0,0 +1,14 @@
+package common
+
+import (
+	"austinhome/internal/logic/events"
+	"austinhome/internal/logic/redact"
+	"fmt"
+)
+
+// Warnf prints a non-fatal warning and records it in the event stream
+func Warnf(format string, args ...any) {
+	message := redact.String(fmt.Sprintf(format, args...))
+	fmt.Printf("Warning: %s\n", message)
+	events.Warning(message)
+}
@@ -0,0 +1,59 @@
+package config
+
+// Chart names within their Helm repositories
+const (
+	IngressNginxChart    = "ingress-nginx"
+	ExternalSecretsChart = "external-secrets"
+	ArgoCDChart          = "argo-cd"
+)
+
+// ChartRef identifies a chart version in a Helm repository
+type ChartRef struct {
+	Name    string
+	RepoURL string
+	Version string
+}
+
+func (r ChartRef) String() string {
+	return r.Name + "-" + r.Version
+}
+
+// Charts returns every chart installed for this config
+func (c *Config) Charts() []ChartRef {
+	components := c.Components
+	return []ChartRef{
+		{Name: IngressNginxChart, RepoURL: components.IngressNginx.RepoURL, Version: components.IngressNginx.Version},
+		{Name: ExternalSecretsChart, RepoURL: components.ExternalSecrets.RepoURL, Version: components.ExternalSecrets.Version},
+		{Name: ArgoCDChart, RepoURL: components.ArgoCD.RepoURL, Version: components.ArgoCD.Version},
+	}
+}
+
+// ClusterURLs returns the remote scripts the cluster provider runs
+func (c *Config) ClusterURLs() []string {
+	if c.Cluster.Provider == ProviderK3s {
+		return []string{c.Cluster.K3sInstallScriptURL()}
+	}
+	return nil
+}
+
+// URLs returns every remote script, manifest and values file used for this config
+func (c *Config) URLs() []string {
+	components := c.Components
+	urls := append(c.ClusterURLs(),
+		components.MetricsServer.ManifestURL(),
+		components.MetalLB.NamespaceURL,
+		components.MetalLB.ManifestURL(),
+		components.MetalLB.IPConfigURL,
+	)
+	// Only the gitlab store is a remote manifest, the others are generated
+	if components.ExternalSecrets.SecretStore.Provider == SecretStoreGitLab {
+		urls = append(urls, components.ExternalSecrets.ClusterSecretStoreURL)
+	}
+	return append(urls,
+		components.CertManager.ManifestURL(),
+		components.CertManager.Route53SecretURL,
+		components.CertManager.ClusterIssuerURL,
+		components.ArgoCD.ValuesURL,
+		components.ArgoCD.OAuthSecretURL,
+	)
+}
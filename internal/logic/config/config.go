@@ -0,0 +1,313 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultFileName is the config file picked up from the working directory when --config is not given
+const DefaultFileName = "austinhome.yaml"
+
+// Cluster providers selectable with cluster.provider
+const (
+	ProviderColima   = "colima"
+	ProviderK3s      = "k3s"
+	ProviderK3d      = "k3d"
+	ProviderKind     = "kind"
+	ProviderExisting = "existing"
+)
+
+// Providers lists every supported cluster.provider value
+var Providers = []string{ProviderColima, ProviderK3s, ProviderK3d, ProviderKind, ProviderExisting}
+
+// Secret store backends selectable with components.externalSecrets.secretStore.provider
+const (
+	SecretStoreGitLab = "gitlab"
+	SecretStoreVault  = "vault"
+	SecretStoreAWS    = "aws"
+	SecretStoreFake   = "fake"
+	SecretStoreFile   = "file"
+)
+
+// SecretStores lists every supported secret store provider
+var SecretStores = []string{SecretStoreGitLab, SecretStoreVault, SecretStoreAWS, SecretStoreFake, SecretStoreFile}
+
+// Vault auth methods selectable with secretStore.vault.auth
+const (
+	VaultAuthToken   = "token"
+	VaultAuthAppRole = "approle"
+)
+
+// Config declares the cluster sizing, component versions and manifest sources used by install
+type Config struct {
+	Cluster    ClusterConfig    `yaml:"cluster"`
+	Network    NetworkConfig    `yaml:"network"`
+	Components ComponentsConfig `yaml:"components"`
+}
+
+type ClusterConfig struct {
+	// Provider creates the cluster, one of Providers
+	Provider string `yaml:"provider"`
+	// Name is the Colima profile, k3d or kind cluster name
+	Name string `yaml:"name"`
+	// CPUs is the number of CPUs assigned to the VM
+	CPUs int `yaml:"cpus"`
+	// Memory is the VM memory in GiB
+	Memory int `yaml:"memory"`
+	// Kubeconfig is the kubeconfig file of the existing provider, empty for the default
+	Kubeconfig string `yaml:"kubeconfig"`
+	// Context is the kubeconfig context of the existing provider, empty for the current one
+	Context string `yaml:"context"`
+	// K3sVersion is the K3s release installed by the k3s provider
+	K3sVersion string `yaml:"k3sVersion"`
+}
+
+// K3sInstallScriptURL returns the K3s install script of the configured release
+func (c ClusterConfig) K3sInstallScriptURL() string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/k3s-io/k3s/v%s/install.sh", strings.ReplaceAll(c.K3sVersion, "+", "%2B"))
+}
+
+type NetworkConfig struct {
+	// Interface is the host interface the VM is bridged to
+	Interface string `yaml:"interface"`
+	// LoadBalancerIP is the address requested for the ingress-nginx LoadBalancer service
+	LoadBalancerIP string `yaml:"loadBalancerIP"`
+}
+
+type ComponentsConfig struct {
+	MetricsServer   MetricsServerConfig   `yaml:"metricsServer"`
+	MetalLB         MetalLBConfig         `yaml:"metallb"`
+	IngressNginx    ChartConfig           `yaml:"ingressNginx"`
+	ExternalSecrets ExternalSecretsConfig `yaml:"externalSecrets"`
+	CertManager     CertManagerConfig     `yaml:"certManager"`
+	ArgoCD          ArgoCDConfig          `yaml:"argocd"`
+}
+
+type MetricsServerConfig struct {
+	Version string `yaml:"version"`
+}
+
+// ManifestURL returns the upstream metrics-server manifest for the configured version
+func (c MetricsServerConfig) ManifestURL() string {
+	return fmt.Sprintf("https://github.com/kubernetes-sigs/metrics-server/releases/download/v%s/components.yaml", c.Version)
+}
+
+type MetalLBConfig struct {
+	Version      string `yaml:"version"`
+	NamespaceURL string `yaml:"namespaceURL"`
+	IPConfigURL  string `yaml:"ipConfigURL"`
+}
+
+// ManifestURL returns the upstream MetalLB native manifest for the configured version
+func (c MetalLBConfig) ManifestURL() string {
+	return fmt.Sprintf("https://raw.githubusercontent.com/metallb/metallb/v%s/config/manifests/metallb-native.yaml", c.Version)
+}
+
+// ChartConfig describes a Helm chart release
+type ChartConfig struct {
+	Version string `yaml:"version"`
+	RepoURL string `yaml:"repoURL"`
+	// Values are applied like helm --set key=value
+	Values map[string]string `yaml:"values"`
+}
+
+// SetValues returns Values as sorted key=value overrides
+func (c ChartConfig) SetValues() []string {
+	keys := make([]string, 0, len(c.Values))
+	for key := range c.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var values []string
+	for _, key := range keys {
+		values = append(values, fmt.Sprintf("%s=%s", key, c.Values[key]))
+	}
+	return values
+}
+
+type ExternalSecretsConfig struct {
+	ChartConfig           `yaml:",inline"`
+	ClusterSecretStoreURL string `yaml:"clusterSecretStoreURL"`
+	// GitLabURL is the GitLab instance the PAT is validated against before install
+	GitLabURL string `yaml:"gitlabURL"`
+	// SecretStore selects the backend the ClusterSecretStore reads from
+	SecretStore SecretStoreConfig `yaml:"secretStore"`
+}
+
+// SecretStoreConfig describes the ClusterSecretStore bootstrapped for ESO.
+// The gitlab provider applies ClusterSecretStoreURL, every other provider
+// generates the store from its own section.
+type SecretStoreConfig struct {
+	// Provider is one of SecretStores
+	Provider string `yaml:"provider"`
+	// Name is the generated ClusterSecretStore name
+	Name  string           `yaml:"name"`
+	Vault VaultStoreConfig `yaml:"vault"`
+	AWS   AWSStoreConfig   `yaml:"aws"`
+	Fake  FakeStoreConfig  `yaml:"fake"`
+	File  FileStoreConfig  `yaml:"file"`
+}
+
+type VaultStoreConfig struct {
+	// Server is the Vault address, e.g. https://vault.example.com:8200
+	Server string `yaml:"server"`
+	// Path is the KV mount secrets are read from
+	Path string `yaml:"path"`
+	// Version is the KV engine version, v1 or v2
+	Version string `yaml:"version"`
+	// Auth is one of VaultAuthToken or VaultAuthAppRole
+	Auth string `yaml:"auth"`
+	// AppRolePath is the AppRole auth mount
+	AppRolePath string `yaml:"appRolePath"`
+	// RoleID is the AppRole role ID, the secret ID is asked for at install
+	RoleID string `yaml:"roleID"`
+}
+
+type AWSStoreConfig struct {
+	// Region is the Secrets Manager region
+	Region string `yaml:"region"`
+}
+
+type FakeStoreConfig struct {
+	// Data is served as-is by the store, for local testing only
+	Data map[string]string `yaml:"data"`
+}
+
+type FileStoreConfig struct {
+	// Path is a YAML file of key: value pairs copied into the cluster
+	Path string `yaml:"path"`
+	// Namespace holds the copied secrets, readable only by the store
+	Namespace string `yaml:"namespace"`
+}
+
+type CertManagerConfig struct {
+	Version          string `yaml:"version"`
+	Route53SecretURL string `yaml:"route53SecretURL"`
+	ClusterIssuerURL string `yaml:"clusterIssuerURL"`
+}
+
+// ManifestURL returns the upstream cert-manager manifest for the configured version
+func (c CertManagerConfig) ManifestURL() string {
+	return fmt.Sprintf("https://github.com/cert-manager/cert-manager/releases/download/v%s/cert-manager.yaml", c.Version)
+}
+
+type ArgoCDConfig struct {
+	ChartConfig    `yaml:",inline"`
+	ValuesURL      string `yaml:"valuesURL"`
+	OAuthSecretURL string `yaml:"oauthSecretURL"`
+}
+
+// Default returns the configuration used when no config file is present
+func Default() *Config {
+	return &Config{
+		Cluster: ClusterConfig{
+			Provider: ProviderColima,
+			Name:     "k3s-homeserver",
+			CPUs:     4,
+			Memory:   8,
+
+			K3sVersion: "1.33.4+k3s1",
+		},
+		Network: NetworkConfig{
+			Interface:      "en1",
+			LoadBalancerIP: "192.168.0.180",
+		},
+		Components: ComponentsConfig{
+			MetricsServer: MetricsServerConfig{
+				Version: "0.8.0",
+			},
+			MetalLB: MetalLBConfig{
+				Version:      "0.15.2",
+				NamespaceURL: "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-metallb/resources/namespace.yaml",
+				IPConfigURL:  "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-metallb/resources/ipconfig.yaml",
+			},
+			IngressNginx: ChartConfig{
+				Version: "4.13.3",
+				RepoURL: "https://kubernetes.github.io/ingress-nginx",
+				Values: map[string]string{
+					"controller.kind":                    "DaemonSet",
+					"controller.progressDeadlineSeconds": "null",
+				},
+			},
+			ExternalSecrets: ExternalSecretsConfig{
+				ChartConfig: ChartConfig{
+					Version: "0.20.2",
+					RepoURL: "https://charts.external-secrets.io",
+				},
+				ClusterSecretStoreURL: "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/app-clustersecrets/resources/gitlab-clustersecretstore.yaml",
+				GitLabURL:             "https://gitlab.com",
+				SecretStore: SecretStoreConfig{
+					Provider: SecretStoreGitLab,
+					Name:     "austinhome",
+					Vault: VaultStoreConfig{
+						Path:        "secret",
+						Version:     "v2",
+						Auth:        VaultAuthToken,
+						AppRolePath: "approle",
+					},
+					File: FileStoreConfig{
+						Namespace: "austinhome-secrets",
+					},
+				},
+			},
+			CertManager: CertManagerConfig{
+				Version:          "1.18.2",
+				Route53SecretURL: "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-cert-manager/resources/route53-secret.yaml",
+				ClusterIssuerURL: "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-cert-manager/resources/cluster-issuer.yaml",
+			},
+			ArgoCD: ArgoCDConfig{
+				ChartConfig: ChartConfig{
+					Version: "8.5.8",
+					RepoURL: "https://argoproj.github.io/argo-helm",
+				},
+				ValuesURL:      "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-argocd/values.yaml",
+				OAuthSecretURL: "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-argocd/resources/oauth-secret.yaml",
+			},
+		},
+	}
+}
+
+// Load reads and validates the config file at path. An empty path falls back to
+// DefaultFileName in the working directory, and to Default() if that does not exist.
+func Load(path string) (*Config, error) {
+	if path == "" {
+		if _, err := os.Stat(DefaultFileName); errors.Is(err, os.ErrNotExist) {
+			return Default(), nil
+		}
+		path = DefaultFileName
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %v", path, err)
+	}
+
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config %s: %v", path, err)
+	}
+
+	fmt.Printf("📄 Loaded configuration from %s\n", path)
+	return cfg, nil
+}
+
+// Parse decodes YAML on top of Default() and validates the result
+func Parse(data []byte) (*Config, error) {
+	cfg := Default()
+
+	decoder := newDecoder(bytes.NewReader(data))
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
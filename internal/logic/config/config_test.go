@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseValid(t *testing.T) {
+	tests := []struct {
+		name  string
+		yaml  string
+		check func(t *testing.T, c *Config)
+	}{
+		{
+			name: "empty keeps the defaults",
+			yaml: "",
+			check: func(t *testing.T, c *Config) {
+				if c.Cluster.Provider != ProviderColima || c.Network.LoadBalancerIP != Default().Network.LoadBalancerIP {
+					t.Errorf("got provider %s and IP %s, want the defaults", c.Cluster.Provider, c.Network.LoadBalancerIP)
+				}
+			},
+		},
+		{
+			name: "overrides merge over the defaults",
+			yaml: `
+cluster:
+  provider: k3d
+  name: lab-2
+  cpus: 2
+network:
+  loadBalancerIP: 10.0.0.50
+components:
+  ingressNginx:
+    version: 4.14.0-beta.1
+    values:
+      controller.replicaCount: "2"
+`,
+			check: func(t *testing.T, c *Config) {
+				if c.Cluster.Provider != ProviderK3d || c.Cluster.Name != "lab-2" || c.Cluster.CPUs != 2 {
+					t.Errorf("cluster is %+v", c.Cluster)
+				}
+				if c.Cluster.Memory != Default().Cluster.Memory {
+					t.Errorf("memory is %d, want the default", c.Cluster.Memory)
+				}
+				if c.Network.LoadBalancerIP != "10.0.0.50" || c.Network.Interface != Default().Network.Interface {
+					t.Errorf("network is %+v", c.Network)
+				}
+				chart := c.Components.IngressNginx
+				if chart.Version != "4.14.0-beta.1" || chart.RepoURL != Default().Components.IngressNginx.RepoURL {
+					t.Errorf("ingress chart is %+v", chart)
+				}
+				if chart.Values["controller.replicaCount"] != "2" || chart.Values["controller.kind"] != "DaemonSet" {
+					t.Errorf("ingress values are %v", chart.Values)
+				}
+			},
+		},
+		{
+			name: "existing cluster",
+			yaml: `
+cluster:
+  provider: existing
+  kubeconfig: /etc/kube/config
+  context: office
+`,
+			check: func(t *testing.T, c *Config) {
+				if c.Cluster.Kubeconfig != "/etc/kube/config" || c.Cluster.Context != "office" {
+					t.Errorf("cluster is %+v", c.Cluster)
+				}
+			},
+		},
+		{
+			name: "k3s build metadata",
+			yaml: "cluster:\n  provider: k3s\n  k3sVersion: 1.34.1+k3s1\n",
+			check: func(t *testing.T, c *Config) {
+				if c.Cluster.K3sVersion != "1.34.1+k3s1" {
+					t.Errorf("k3s version is %s", c.Cluster.K3sVersion)
+				}
+			},
+		},
+		{
+			name: "vault approle store",
+			yaml: `
+components:
+  externalSecrets:
+    secretStore:
+      provider: vault
+      vault:
+        server: https://vault.example.com
+        auth: approle
+        roleID: 1234
+`,
+			check: func(t *testing.T, c *Config) {
+				vault := c.Components.ExternalSecrets.SecretStore.Vault
+				if vault.Server != "https://vault.example.com" || vault.Path != "secret" || vault.RoleID != "1234" {
+					t.Errorf("vault is %+v", vault)
+				}
+			},
+		},
+		{
+			name: "aws store",
+			yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: aws\n      aws:\n        region: eu-west-1\n",
+			check: func(t *testing.T, c *Config) {
+				if c.Components.ExternalSecrets.SecretStore.AWS.Region != "eu-west-1" {
+					t.Errorf("aws is %+v", c.Components.ExternalSecrets.SecretStore.AWS)
+				}
+			},
+		},
+		{
+			name: "file store",
+			yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: file\n      file:\n        path: ./secrets.yaml\n",
+			check: func(t *testing.T, c *Config) {
+				if c.Components.ExternalSecrets.SecretStore.File.Namespace != "austinhome-secrets" {
+					t.Errorf("file is %+v", c.Components.ExternalSecrets.SecretStore.File)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse([]byte(tt.yaml))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		// want are the fields every problem of the ValidationError names, in order
+		want []string
+		// decode is part of the decoding error when the YAML does not fit Config
+		decode string
+	}{
+		{name: "unknown top level key", yaml: "clusters:\n  name: lab\n", decode: "field clusters not found"},
+		{name: "misspelled key", yaml: "network:\n  loadBalancerIp: 10.0.0.1\n", decode: "field loadBalancerIp not found"},
+		{name: "unknown store key", yaml: "components:\n  externalSecrets:\n    secretStore:\n      vault:\n        token: x\n", decode: "field token not found"},
+		{name: "wrong type", yaml: "cluster:\n  cpus: four\n", decode: "cannot unmarshal"},
+		{name: "unknown provider", yaml: "cluster:\n  provider: minikube\n", want: []string{"cluster.provider"}},
+		{name: "kubeconfig without existing", yaml: "cluster:\n  provider: k3d\n  context: office\n", want: []string{"cluster.kubeconfig"}},
+		{name: "cluster name", yaml: "cluster:\n  name: Home_Lab\n", want: []string{"cluster.name"}},
+		{name: "sizes", yaml: "cluster:\n  cpus: 0\n  memory: -1\n", want: []string{"cluster.cpus", "cluster.memory"}},
+		{name: "leading v", yaml: "cluster:\n  k3sVersion: v1.33.4+k3s1\n", want: []string{"cluster.k3sVersion"}},
+		{name: "short version", yaml: "components:\n  metallb:\n    version: \"0.15\"\n", want: []string{"components.metallb.version"}},
+		{name: "chart versions", yaml: "components:\n  ingressNginx:\n    version: latest\n  argocd:\n    version: \"\"\n", want: []string{"components.ingressNginx.version", "components.argocd.version"}},
+		{name: "empty interface", yaml: "network:\n  interface: \" \"\n", want: []string{"network.interface"}},
+		{name: "not an IP", yaml: "network:\n  loadBalancerIP: ingress.local\n", want: []string{"network.loadBalancerIP"}},
+		{name: "IPv6", yaml: "network:\n  loadBalancerIP: \"fd00::10\"\n", want: []string{"network.loadBalancerIP"}},
+		{name: "CIDR instead of IP", yaml: "network:\n  loadBalancerIP: 192.168.0.180/32\n", want: []string{"network.loadBalancerIP"}},
+		{name: "relative URL", yaml: "components:\n  certManager:\n    clusterIssuerURL: cluster-issuer.yaml\n", want: []string{"components.certManager.clusterIssuerURL"}},
+		{name: "unknown store", yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: onepassword\n", want: []string{"components.externalSecrets.secretStore.provider"}},
+		{
+			name: "incomplete vault store",
+			yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: vault\n      vault:\n        version: v3\n        auth: approle\n",
+			want: []string{
+				"components.externalSecrets.secretStore.vault.server",
+				"components.externalSecrets.secretStore.vault.version",
+				"components.externalSecrets.secretStore.vault.roleID",
+			},
+		},
+		{name: "aws without region", yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: aws\n", want: []string{"components.externalSecrets.secretStore.aws.region"}},
+		{name: "fake without data", yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: fake\n", want: []string{"components.externalSecrets.secretStore.fake.data"}},
+		{
+			name: "file store",
+			yaml: "components:\n  externalSecrets:\n    secretStore:\n      provider: file\n      name: My Store\n      file:\n        namespace: Secrets\n",
+			want: []string{
+				"components.externalSecrets.secretStore.name",
+				"components.externalSecrets.secretStore.file.path",
+				"components.externalSecrets.secretStore.file.namespace",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("Parse accepted the config")
+			}
+
+			var validation *ValidationError
+			if tt.decode != "" {
+				if errors.As(err, &validation) || !strings.Contains(err.Error(), tt.decode) {
+					t.Fatalf("got %v, want a decoding error containing %q", err, tt.decode)
+				}
+				return
+			}
+
+			if !errors.As(err, &validation) {
+				t.Fatalf("got %v, want a ValidationError", err)
+			}
+			if len(validation.Problems) != len(tt.want) {
+				t.Fatalf("got problems %q, want one for each of %q", validation.Problems, tt.want)
+			}
+			for i, field := range tt.want {
+				if !strings.HasPrefix(validation.Problems[i], field+": ") {
+					t.Errorf("problem %q is not about %s", validation.Problems[i], field)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	// Without a file in the working directory the defaults apply
+	c, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if c.Cluster.Name != Default().Cluster.Name {
+		t.Fatalf("got cluster %s, want the default", c.Cluster.Name)
+	}
+
+	if err := os.WriteFile(DefaultFileName, []byte("cluster:\n  name: from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = Load(""); err != nil || c.Cluster.Name != "from-file" {
+		t.Fatalf("got %v (%v), want the cluster of %s", c, err, DefaultFileName)
+	}
+
+	path := filepath.Join(dir, "broken.yaml")
+	if err := os.WriteFile(path, []byte("cluster:\n  nmae: typo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Fatalf("got %v, want an error naming %s", err, path)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Fatal("Load accepted a missing file")
+	}
+}
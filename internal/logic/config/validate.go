@@ -0,0 +1,173 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+([-+][0-9A-Za-z.-]+)?$`)
+	namePattern    = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+)
+
+func newDecoder(r io.Reader) *yaml.Decoder {
+	decoder := yaml.NewDecoder(r)
+	// Reject unknown keys so typos do not silently fall back to defaults
+	decoder.KnownFields(true)
+	return decoder
+}
+
+// ValidationError lists every invalid field found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d problem(s) found:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) fail(field, format string, args ...any) {
+	v.problems = append(v.problems, fmt.Sprintf("%s: %s", field, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) positive(field string, value int) {
+	if value <= 0 {
+		v.fail(field, "must be a positive integer, got %d", value)
+	}
+}
+
+func (v *validator) version(field, value string) {
+	if !versionPattern.MatchString(value) {
+		v.fail(field, "must be a version like 1.2.3 without a leading v, got %q", value)
+	}
+}
+
+func (v *validator) url(field, value string) {
+	parsed, err := url.Parse(value)
+	if value == "" || err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		v.fail(field, "must be an http(s) URL, got %q", value)
+	}
+}
+
+func (v *validator) chart(field string, chart ChartConfig) {
+	v.version(field+".version", chart.Version)
+	v.url(field+".repoURL", chart.RepoURL)
+	for key := range chart.Values {
+		if strings.TrimSpace(key) == "" {
+			v.fail(field+".values", "keys must not be empty")
+		}
+	}
+}
+
+func (v *validator) secretStore(field string, eso ExternalSecretsConfig) {
+	store := eso.SecretStore
+	field += ".secretStore"
+
+	if store.Provider != SecretStoreGitLab && !namePattern.MatchString(store.Name) {
+		v.fail(field+".name", "must contain only lowercase letters, digits and dashes, got %q", store.Name)
+	}
+
+	switch store.Provider {
+	case SecretStoreGitLab:
+		v.url("components.externalSecrets.clusterSecretStoreURL", eso.ClusterSecretStoreURL)
+		v.url("components.externalSecrets.gitlabURL", eso.GitLabURL)
+	case SecretStoreVault:
+		v.url(field+".vault.server", store.Vault.Server)
+		if strings.TrimSpace(store.Vault.Path) == "" {
+			v.fail(field+".vault.path", "must not be empty")
+		}
+		if store.Vault.Version != "v1" && store.Vault.Version != "v2" {
+			v.fail(field+".vault.version", "must be v1 or v2, got %q", store.Vault.Version)
+		}
+		switch store.Vault.Auth {
+		case VaultAuthToken:
+		case VaultAuthAppRole:
+			if store.Vault.RoleID == "" {
+				v.fail(field+".vault.roleID", "is required for %s auth", VaultAuthAppRole)
+			}
+			if store.Vault.AppRolePath == "" {
+				v.fail(field+".vault.appRolePath", "is required for %s auth", VaultAuthAppRole)
+			}
+		default:
+			v.fail(field+".vault.auth", "must be %s or %s, got %q", VaultAuthToken, VaultAuthAppRole, store.Vault.Auth)
+		}
+	case SecretStoreAWS:
+		if strings.TrimSpace(store.AWS.Region) == "" {
+			v.fail(field+".aws.region", "must not be empty")
+		}
+	case SecretStoreFake:
+		if len(store.Fake.Data) == 0 {
+			v.fail(field+".fake.data", "must contain at least one key")
+		}
+	case SecretStoreFile:
+		if strings.TrimSpace(store.File.Path) == "" {
+			v.fail(field+".file.path", "must not be empty")
+		}
+		if !namePattern.MatchString(store.File.Namespace) {
+			v.fail(field+".file.namespace", "must contain only lowercase letters, digits and dashes, got %q", store.File.Namespace)
+		}
+	default:
+		v.fail(field+".provider", "must be one of %s, got %q", strings.Join(SecretStores, ", "), store.Provider)
+	}
+}
+
+// Validate checks every field and returns a *ValidationError describing all problems
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	if !slices.Contains(Providers, c.Cluster.Provider) {
+		v.fail("cluster.provider", "must be one of %s, got %q", strings.Join(Providers, ", "), c.Cluster.Provider)
+	}
+	if c.Cluster.Provider != ProviderExisting && (c.Cluster.Kubeconfig != "" || c.Cluster.Context != "") {
+		v.fail("cluster.kubeconfig", "kubeconfig and context are only used by the %s provider", ProviderExisting)
+	}
+	if !namePattern.MatchString(c.Cluster.Name) {
+		v.fail("cluster.name", "must contain only lowercase letters, digits and dashes, got %q", c.Cluster.Name)
+	}
+	v.positive("cluster.cpus", c.Cluster.CPUs)
+	v.positive("cluster.memory", c.Cluster.Memory)
+	v.version("cluster.k3sVersion", c.Cluster.K3sVersion)
+
+	if strings.TrimSpace(c.Network.Interface) == "" {
+		v.fail("network.interface", "must not be empty")
+	}
+	if ip := net.ParseIP(c.Network.LoadBalancerIP); ip == nil || ip.To4() == nil {
+		v.fail("network.loadBalancerIP", "must be an IPv4 address, got %q", c.Network.LoadBalancerIP)
+	}
+
+	components := c.Components
+	v.version("components.metricsServer.version", components.MetricsServer.Version)
+
+	v.version("components.metallb.version", components.MetalLB.Version)
+	v.url("components.metallb.namespaceURL", components.MetalLB.NamespaceURL)
+	v.url("components.metallb.ipConfigURL", components.MetalLB.IPConfigURL)
+
+	v.chart("components.ingressNginx", components.IngressNginx)
+
+	v.chart("components.externalSecrets", components.ExternalSecrets.ChartConfig)
+	v.secretStore("components.externalSecrets", components.ExternalSecrets)
+
+	v.version("components.certManager.version", components.CertManager.Version)
+	v.url("components.certManager.route53SecretURL", components.CertManager.Route53SecretURL)
+	v.url("components.certManager.clusterIssuerURL", components.CertManager.ClusterIssuerURL)
+
+	v.chart("components.argocd", components.ArgoCD.ChartConfig)
+	v.url("components.argocd.valuesURL", components.ArgoCD.ValuesURL)
+	v.url("components.argocd.oauthSecretURL", components.ArgoCD.OAuthSecretURL)
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
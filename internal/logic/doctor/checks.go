@@ -0,0 +1,403 @@
+package doctor
+
+import (
+	"austinhome/internal/logic/config"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// minDiskGiB is the free space below which images and volumes will not fit
+	minDiskGiB = 10
+	// recommendedDiskGiB leaves room for the VM disk to grow
+	recommendedDiskGiB = 30
+
+	dialTimeout   = time.Second
+	lookupTimeout = 5 * time.Second
+)
+
+// check is a named entry of the catalog
+type check struct {
+	name string
+	run  func(h *host) Result
+}
+
+// tool is a binary the provider uses beyond its prerequisites
+type tool struct {
+	name    string
+	version []string
+	// hint explains how to get the tool. Tools installed by the tooling step
+	// only warn when missing.
+	hint      string
+	installed bool
+}
+
+// catalog returns the checks that apply to the provider
+func catalog(h *host, offline bool) []check {
+	checks := []check{{name: "prerequisites", run: prerequisites}}
+	for _, t := range tools(h.cfg.Cluster.Provider) {
+		checks = append(checks, check{name: t.name, run: t.check})
+	}
+
+	switch h.cfg.Cluster.Provider {
+	case config.ProviderColima:
+		checks = append(checks,
+			check{name: "cpu", run: cpus},
+			check{name: "memory", run: memory},
+			check{name: "disk", run: disk},
+			check{name: "network-interface", run: networkInterface},
+			check{name: "port-80", run: hostPort80},
+			check{name: "colima-profile", run: colimaProfile},
+		)
+	case config.ProviderK3d, config.ProviderKind:
+		checks = append(checks,
+			check{name: "docker-daemon", run: dockerDaemon},
+			check{name: "disk", run: disk},
+		)
+	case config.ProviderK3s:
+		checks = append(checks,
+			check{name: "disk", run: disk},
+			check{name: "port-80", run: hostPort80},
+		)
+	}
+
+	checks = append(checks,
+		check{name: "load-balancer-ip", run: loadBalancerIP},
+		check{name: "kube-context", run: kubeContext},
+	)
+	if !offline {
+		checks = append(checks, check{name: "dns", run: resolveHosts})
+	}
+	return checks
+}
+
+// tools lists the binaries of the provider not covered by its Prerequisites
+func tools(provider string) []tool {
+	kubectl := tool{name: "kubectl", version: []string{"version", "--client"}, hint: "install it with 'brew install kubectl'"}
+	switch provider {
+	case config.ProviderColima:
+		return []tool{{name: "colima", version: []string{"version"}, hint: "installed with Homebrew by 'austinhome install'", installed: true}, kubectl}
+	case config.ProviderK3d:
+		return []tool{{name: "k3d", version: []string{"version"}, hint: "installed with Homebrew by 'austinhome install'", installed: true}, kubectl}
+	case config.ProviderKind:
+		return []tool{{name: "kind", version: []string{"version"}, hint: "installed with Homebrew by 'austinhome install'", installed: true}, kubectl}
+	case config.ProviderK3s:
+		kubectl.hint, kubectl.installed = "shipped with K3s by 'austinhome install'", true
+		return []tool{kubectl}
+	default:
+		return nil
+	}
+}
+
+func (t tool) check(h *host) Result {
+	path, err := h.runner.LookPath(t.name)
+	if err != nil {
+		if t.installed {
+			return warn(t.hint, "not installed")
+		}
+		return fail(t.hint, "not found in PATH")
+	}
+
+	output, err := h.runner.RunOutput(t.name, t.version...)
+	if err != nil {
+		return warn("check that "+path+" runs", "found at %s but '%s %s' failed: %v", path, t.name, strings.Join(t.version, " "), err)
+	}
+	return pass("%s (%s)", firstLine(output), path)
+}
+
+func prerequisites(h *host) Result {
+	if err := h.provider.Prerequisites(); err != nil {
+		return fail("install the missing tools and run the check again", "%v", err)
+	}
+	return pass("%s provider can run on this host", h.provider.Name())
+}
+
+func cpus(h *host) Result {
+	requested, available := h.cfg.Cluster.CPUs, h.probe.NumCPU()
+	hint := "lower cluster.cpus in " + config.DefaultFileName
+	switch {
+	case requested > available:
+		return fail(hint, "%d CPUs requested but the host has %d", requested, available)
+	case requested == available:
+		return warn(hint, "all %d CPUs of the host go to the VM", available)
+	}
+	return pass("%d of %d CPUs", requested, available)
+}
+
+func memory(h *host) Result {
+	total, available, err := h.probe.Memory()
+	if err != nil {
+		return warn("", "could not read the host memory: %v", err)
+	}
+
+	requested := uint64(h.cfg.Cluster.Memory) << 30
+	hint := "lower cluster.memory in " + config.DefaultFileName + " or close other applications"
+	switch {
+	case requested >= total:
+		return fail(hint, "%d GiB requested but the host has %s", h.cfg.Cluster.Memory, gib(total))
+	case requested > available:
+		return warn(hint, "%d GiB requested but only %s of %s is free", h.cfg.Cluster.Memory, gib(available), gib(total))
+	}
+	return pass("%d GiB requested, %s of %s free", h.cfg.Cluster.Memory, gib(available), gib(total))
+}
+
+func disk(h *host) Result {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return warn("", "failed to get home directory: %v", err)
+	}
+	free, err := h.freeDisk(homeDir)
+	if err != nil {
+		return warn("", "could not read the free space of %s: %v", homeDir, err)
+	}
+
+	hint := "free up space on the volume holding " + homeDir
+	switch {
+	case free < minDiskGiB<<30:
+		return fail(hint, "only %s free, at least %d GiB is needed", gib(free), minDiskGiB)
+	case free < recommendedDiskGiB<<30:
+		return warn(hint, "only %s free, %d GiB is recommended", gib(free), recommendedDiskGiB)
+	}
+	return pass("%s free in %s", gib(free), homeDir)
+}
+
+func networkInterface(h *host) Result {
+	name := h.cfg.Network.Interface
+	ifaces, err := h.probe.Interfaces()
+	if err != nil {
+		return warn("", "could not list the network interfaces: %v", err)
+	}
+	hint := fmt.Sprintf("set network.interface in %s to one of: %s", config.DefaultFileName, strings.Join(upInterfaces(ifaces), ", "))
+
+	iface := findInterface(ifaces, name)
+	if iface == nil {
+		return fail(hint, "interface %s not found", name)
+	}
+	if !iface.Up {
+		return fail(hint, "interface %s is down", name)
+	}
+
+	subnet := iface.Subnet
+	if subnet == nil {
+		return warn(hint, "interface %s has no IPv4 address, the bridged VM will not get one", name)
+	}
+	return pass("%s is up with %s", name, subnet)
+}
+
+// hostPort80 checks that nothing on the host already serves HTTP, which the
+// cluster's ingress would compete with
+func hostPort80(h *host) Result {
+	if err := h.probe.Dial("127.0.0.1:80"); err != nil {
+		return pass("port 80 is free")
+	}
+
+	if h.clusterRunning() {
+		return pass("port 80 is presumably served by the running cluster")
+	}
+
+	// Native K3s binds the ingress to the host ports itself
+	hint := "stop the local web server (see 'sudo lsof -iTCP:80 -sTCP:LISTEN')"
+	if h.cfg.Cluster.Provider == config.ProviderK3s {
+		return fail(hint, "port 80 is already taken on this host")
+	}
+	return warn(hint, "port 80 is already taken on this host")
+}
+
+// loadBalancerIP checks that the address requested for ingress-nginx is
+// neither this host nor another machine on the network
+func loadBalancerIP(h *host) Result {
+	address := h.cfg.Network.LoadBalancerIP
+	ip := net.ParseIP(address)
+	hint := fmt.Sprintf("set network.loadBalancerIP in %s to a free address, and keep the MetalLB pool in sync", config.DefaultFileName)
+
+	ifaces, _ := h.probe.Interfaces()
+	for _, iface := range ifaces {
+		if slices.ContainsFunc(iface.Addrs, ip.Equal) {
+			return fail(hint, "%s is an address of this host", address)
+		}
+	}
+
+	if h.cfg.Cluster.Provider == config.ProviderColima {
+		if iface := findInterface(ifaces, h.cfg.Network.Interface); iface != nil {
+			if subnet := iface.Subnet; subnet != nil && !subnet.Contains(ip) {
+				return warn(hint, "%s is outside %s (%s), the bridged VM cannot announce it", address, subnet, h.cfg.Network.Interface)
+			}
+		}
+	}
+
+	if !h.addressInUse(address) {
+		return pass("%s is free", address)
+	}
+	if h.clusterRunning() {
+		return warn("nothing to do if it is the ingress of this cluster, otherwise "+hint, "%s answers, presumably the ingress of the running cluster", address)
+	}
+	return fail(hint, "%s is already in use on the network", address)
+}
+
+// kubeContext checks the context the provider writes or reads in kubeconfig
+func kubeContext(h *host) Result {
+	path, contextName := h.provider.Kubeconfig()
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if path != "" {
+		rules.ExplicitPath = path
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if !h.provider.Managed() {
+				return fail("check --kubeconfig or cluster.kubeconfig", "kubeconfig %s does not exist", path)
+			}
+			return pass("%s is created with the cluster", path)
+		}
+	}
+
+	kubeconfig, err := rules.Load()
+	if err != nil {
+		return fail("fix or move the broken kubeconfig file", "failed to load kubeconfig: %v", err)
+	}
+
+	if !h.provider.Managed() {
+		if contextName == "" {
+			contextName = kubeconfig.CurrentContext
+		}
+		if _, ok := kubeconfig.Contexts[contextName]; contextName == "" || !ok {
+			return fail("pass --kube-context with one of the contexts from 'kubectl config get-contexts'", "context %q is not defined", contextName)
+		}
+		if !h.clusterRunning() {
+			return fail("check that the cluster is up and the credentials are valid", "the cluster behind context %s does not answer", contextName)
+		}
+		return pass("context %s reaches the cluster", contextName)
+	}
+
+	if _, ok := kubeconfig.Contexts[contextName]; !ok {
+		return pass("context %s is free", contextName)
+	}
+	if h.clusterRunning() {
+		return pass("context %s reaches the running cluster, install reuses it", contextName)
+	}
+	return warn(fmt.Sprintf("if it belongs to another cluster, remove it with 'kubectl config delete-context %s'", contextName),
+		"context %s already exists but does not answer, it is overwritten when the cluster is created", contextName)
+}
+
+// resolveHosts checks that every chart repository and manifest host resolves
+func resolveHosts(h *host) Result {
+	var hosts []string
+	urls := h.cfg.URLs()
+	for _, chart := range h.cfg.Charts() {
+		urls = append(urls, chart.RepoURL)
+	}
+	for _, raw := range urls {
+		if u, err := url.Parse(raw); err == nil && u.Hostname() != "" && !slices.Contains(hosts, u.Hostname()) {
+			hosts = append(hosts, u.Hostname())
+		}
+	}
+
+	var unresolved []string
+	for _, name := range hosts {
+		ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+		err := h.probe.LookupHost(ctx, name)
+		cancel()
+		if err != nil {
+			unresolved = append(unresolved, name)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return fail("check the DNS settings and network connection, or install offline with --bundle",
+			"cannot resolve %s", strings.Join(unresolved, ", "))
+	}
+	return pass("%d chart and manifest hosts resolve", len(hosts))
+}
+
+func dockerDaemon(h *host) Result {
+	if _, err := h.runner.LookPath("docker"); err != nil {
+		return fail("install Docker Desktop, Colima or OrbStack", "docker is not installed")
+	}
+	if _, err := h.runner.RunOutput("docker", "info", "--format", "{{.ServerVersion}}"); err != nil {
+		return fail("start Docker Desktop or 'colima start'", "the Docker daemon does not answer")
+	}
+	return pass("the Docker daemon is running")
+}
+
+// colimaProfile reports an existing Colima instance of the configured name,
+// which install reuses while running and otherwise deletes
+func colimaProfile(h *host) Result {
+	name := h.cfg.Cluster.Name
+	if _, err := h.runner.LookPath("colima"); err != nil {
+		return pass("no profile %s, Colima is not installed yet", name)
+	}
+
+	output, err := h.runner.RunOutput("colima", "list", "--json")
+	if err != nil {
+		return warn("check 'colima list'", "failed to list Colima profiles: %v", err)
+	}
+
+	// colima list --json prints one object per line
+	for _, line := range strings.Split(output, "\n") {
+		var profile struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		}
+		if json.Unmarshal([]byte(line), &profile) != nil || profile.Name != name {
+			continue
+		}
+
+		if profile.Status == "Running" {
+			return pass("profile %s is running, install reuses it (--recreate-cluster replaces it)", name)
+		}
+		return warn("back up anything you need from it, or set a different cluster.name",
+			"profile %s exists (%s) and is deleted and recreated by install", name, profile.Status)
+	}
+	return pass("no profile %s yet", name)
+}
+
+// addressInUse reports whether a machine answers on address, either by
+// accepting or by refusing a connection to the HTTP ports
+func (h *host) addressInUse(address string) bool {
+	for _, port := range []string{"80", "443"} {
+		err := h.probe.Dial(net.JoinHostPort(address, port))
+		if err == nil {
+			return true
+		}
+		if isConnectionRefused(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// findInterface returns the interface called name, nil if there is none
+func findInterface(ifaces []Interface, name string) *Interface {
+	for i := range ifaces {
+		if ifaces[i].Name == name {
+			return &ifaces[i]
+		}
+	}
+	return nil
+}
+
+// upInterfaces lists the interfaces that could be bridged to the VM
+func upInterfaces(ifaces []Interface) []string {
+	var names []string
+	for _, iface := range ifaces {
+		if iface.Up && !iface.Loopback && iface.Subnet != nil {
+			names = append(names, iface.Name)
+		}
+	}
+	return names
+}
+
+func firstLine(output string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(output), "\n", 2)[0])
+}
+
+func gib(bytes uint64) string {
+	return fmt.Sprintf("%.1f GiB", float64(bytes)/(1<<30))
+}
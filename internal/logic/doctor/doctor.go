@@ -0,0 +1,139 @@
+// Package doctor diagnoses the host before an install. Missing binaries, too
+// little memory, a wrong network interface or an address already in use are
+// reported up front instead of surfacing halfway through the install.
+package doctor
+
+import (
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/events"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Check statuses
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// Result is the outcome of a single check
+type Result struct {
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	// Hint explains how to fix a warning or failure
+	Hint string `json:"hint,omitempty"`
+}
+
+// Options controls how Execute diagnoses the host
+type Options struct {
+	// Runner executes the probing commands, defaults to common.ExecRunner
+	Runner common.Runner
+	// Config declares the sizing and network checked against, defaults to config.Default()
+	Config *config.Config
+	// Offline skips resolving the chart and manifest hosts, for installs from a bundle
+	Offline bool
+}
+
+// Execute runs every check for the configured provider, prints the results and
+// returns them along with an error if any check failed
+func Execute(opts Options) ([]Result, error) {
+	if opts.Runner == nil {
+		opts.Runner = common.ExecRunner{}
+	}
+	common.SetRunner(opts.Runner)
+
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = config.Default()
+	}
+	provider, err := cluster.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cluster.Bind(provider)
+
+	return Run(opts.Runner, cfg, provider, opts.Offline)
+}
+
+// Run executes the check catalog for provider with runner, which must really
+// run its commands since every probe only reads the host. Install uses it as
+// its preflight stage.
+func Run(runner common.Runner, cfg *config.Config, provider cluster.Provider, offline bool) ([]Result, error) {
+	fmt.Println("🩺 Checking the host...")
+
+	p := probe
+	if p == nil {
+		p = SystemProbe{Runner: runner}
+	}
+	h := &host{runner: runner, cfg: cfg, provider: provider, probe: p}
+	var results []Result
+	var failed []string
+	for _, c := range catalog(h, offline) {
+		result := c.run(h)
+		result.Check = c.name
+		results = append(results, result)
+
+		switch result.Status {
+		case StatusFail:
+			failed = append(failed, c.name)
+		case StatusWarn:
+			// Marks the running install step as warn without printing it twice
+			events.Warning(c.name + ": " + result.Message)
+		}
+		events.Emit(events.Event{Type: events.TypeCheck, Status: result.Status, Message: result.Message, Data: result})
+	}
+
+	fmt.Println()
+	renderTable(os.Stdout, results)
+
+	if len(failed) > 0 {
+		return results, fmt.Errorf("%d check(s) failed: %s", len(failed), strings.Join(failed, ", "))
+	}
+	return results, nil
+}
+
+func renderTable(out io.Writer, results []Result) {
+	icons := map[string]string{
+		StatusPass: "✅ pass",
+		StatusWarn: "⚠️ warn",
+		StatusFail: "❌ fail",
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tMESSAGE")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Check, icons[result.Status], result.Message)
+	}
+	w.Flush()
+
+	printed := false
+	for _, result := range results {
+		if result.Status == StatusPass || result.Hint == "" {
+			continue
+		}
+		if !printed {
+			fmt.Fprintln(out)
+			printed = true
+		}
+		fmt.Fprintf(out, "💡 %s: %s\n", result.Check, result.Hint)
+	}
+}
+
+func pass(format string, args ...any) Result {
+	return Result{Status: StatusPass, Message: fmt.Sprintf(format, args...)}
+}
+
+func warn(hint, format string, args ...any) Result {
+	return Result{Status: StatusWarn, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
+
+func fail(hint, format string, args ...any) Result {
+	return Result{Status: StatusFail, Message: fmt.Sprintf(format, args...), Hint: hint}
+}
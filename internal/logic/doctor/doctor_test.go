@@ -0,0 +1,368 @@
+package doctor
+
+import (
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const kubeconfigWithContext = `apiVersion: v1
+kind: Config
+current-context: home
+clusters:
+- name: home
+  cluster:
+    server: https://192.0.2.1:6443
+users:
+- name: home
+  user:
+    token: secret
+contexts:
+- name: home
+  context:
+    cluster: home
+    user: home
+`
+
+// fakeProbe describes a host without touching the real one. Addresses missing
+// from dial time out, like a free address on the network.
+type fakeProbe struct {
+	cpus             int
+	total, available uint64
+	interfaces       []Interface
+	dial             map[string]error
+	unresolved       []string
+}
+
+func (p *fakeProbe) NumCPU() int {
+	return p.cpus
+}
+
+func (p *fakeProbe) Memory() (uint64, uint64, error) {
+	return p.total, p.available, nil
+}
+
+func (p *fakeProbe) Dial(address string) error {
+	if err, ok := p.dial[address]; ok {
+		return err
+	}
+	return errors.New("i/o timeout")
+}
+
+func (p *fakeProbe) LookupHost(_ context.Context, name string) error {
+	if slices.Contains(p.unresolved, name) {
+		return fmt.Errorf("lookup %s: no such host", name)
+	}
+	return nil
+}
+
+func (p *fakeProbe) Interfaces() ([]Interface, error) {
+	return p.interfaces, nil
+}
+
+// healthyProbe has room for the default cluster on en1 in 192.168.0.0/24
+func healthyProbe() *fakeProbe {
+	return &fakeProbe{
+		cpus:      8,
+		total:     32 << 30,
+		available: 24 << 30,
+		interfaces: []Interface{
+			{Name: "lo0", Up: true, Loopback: true, Subnet: mustCIDR("127.0.0.1/8"), Addrs: []net.IP{net.ParseIP("127.0.0.1")}},
+			{Name: "en1", Up: true, Subnet: mustCIDR("192.168.0.10/24"), Addrs: []net.IP{net.ParseIP("192.168.0.10")}},
+		},
+	}
+}
+
+func mustCIDR(s string) *net.IPNet {
+	ip, subnet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	subnet.IP = ip
+	return subnet
+}
+
+// dfOutput is the POSIX df output of a volume with gib GiB available
+func dfOutput(gib uint64) common.FakeResponse {
+	return common.FakeResponse{Stdout: fmt.Sprintf("Filesystem 1024-blocks Used Available Capacity Mounted on\n/dev/disk1 999999999 1000 %d 1%% /\n", gib<<20)}
+}
+
+func TestChecks(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+
+	tests := []struct {
+		name     string
+		provider string
+		// kubeconfig is written to KUBECONFIG when set
+		kubeconfig string
+		// running adds a node to the cluster the checks reach
+		running bool
+		offline bool
+		// script adjusts the host, which starts out healthy
+		script func(c *config.Config, r *common.FakeRunner, p *fakeProbe)
+		want   map[string]string
+		absent []string
+	}{
+		{
+			name:     "colima pass",
+			provider: config.ProviderColima,
+			offline:  true,
+			want: map[string]string{
+				"prerequisites": StatusPass, "colima": StatusPass, "kubectl": StatusPass,
+				"cpu": StatusPass, "memory": StatusPass, "disk": StatusPass, "network-interface": StatusPass,
+				"port-80": StatusPass, "colima-profile": StatusPass, "load-balancer-ip": StatusPass, "kube-context": StatusPass,
+			},
+			absent: []string{"docker-daemon", "dns"},
+		},
+		{
+			name:     "colima warn",
+			provider: config.ProviderColima,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.Missing("colima")
+				r.On("df", dfOutput(20))
+				c.Cluster.CPUs = p.cpus
+				p.available = 4 << 30
+				p.interfaces[1].Subnet = mustCIDR("10.0.0.5/24")
+				p.dial["127.0.0.1:80"] = nil
+			},
+			want: map[string]string{
+				"colima": StatusWarn, "cpu": StatusWarn, "memory": StatusWarn, "disk": StatusWarn,
+				"port-80": StatusWarn, "load-balancer-ip": StatusWarn, "colima-profile": StatusPass,
+			},
+		},
+		{
+			name:     "colima fail",
+			provider: config.ProviderColima,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.Missing("brew", "kubectl")
+				r.On("df", dfOutput(5))
+				c.Cluster.CPUs = p.cpus + 1
+				p.total = 8 << 30
+				c.Network.Interface = "en9"
+				p.interfaces[1].Addrs = append(p.interfaces[1].Addrs, net.ParseIP(c.Network.LoadBalancerIP))
+			},
+			want: map[string]string{
+				"prerequisites": StatusFail, "kubectl": StatusFail, "cpu": StatusFail, "memory": StatusFail,
+				"disk": StatusFail, "network-interface": StatusFail, "load-balancer-ip": StatusFail,
+			},
+		},
+		{
+			name:     "colima interface down",
+			provider: config.ProviderColima,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				p.interfaces[1].Up = false
+			},
+			want: map[string]string{"network-interface": StatusFail},
+		},
+		{
+			name:     "colima stopped profile",
+			provider: config.ProviderColima,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.On("colima list --json", common.FakeResponse{Stdout: `{"name":"` + c.Cluster.Name + `","status":"Stopped"}` + "\n"})
+			},
+			want: map[string]string{"colima-profile": StatusWarn},
+		},
+		{
+			name:     "k3d pass",
+			provider: config.ProviderK3d,
+			offline:  true,
+			want: map[string]string{
+				"prerequisites": StatusPass, "k3d": StatusPass, "kubectl": StatusPass, "docker-daemon": StatusPass,
+				"disk": StatusPass, "load-balancer-ip": StatusPass, "kube-context": StatusPass,
+			},
+			absent: []string{"cpu", "memory", "network-interface", "port-80", "colima-profile"},
+		},
+		{
+			name:     "k3d without docker",
+			provider: config.ProviderK3d,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.Missing("docker", "k3d")
+			},
+			want: map[string]string{"prerequisites": StatusFail, "docker-daemon": StatusFail, "k3d": StatusWarn},
+		},
+		{
+			name:     "kind with a stopped daemon",
+			provider: config.ProviderKind,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.On("docker info", common.FakeResponse{ExitCode: 1})
+				r.On("kind version", common.FakeResponse{ExitCode: 1})
+			},
+			want: map[string]string{"prerequisites": StatusPass, "docker-daemon": StatusFail, "kind": StatusWarn},
+		},
+		{
+			name:     "k3s port 80 taken",
+			provider: config.ProviderK3s,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.Missing("kubectl")
+				p.dial["127.0.0.1:80"] = nil
+			},
+			want:   map[string]string{"kubectl": StatusWarn, "disk": StatusPass, "port-80": StatusFail},
+			absent: []string{"cpu", "memory", "docker-daemon"},
+		},
+		{
+			name:     "k3s port 80 served by the running cluster",
+			provider: config.ProviderK3s,
+			running:  true,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				p.dial["127.0.0.1:80"] = nil
+			},
+			want: map[string]string{"port-80": StatusPass},
+		},
+		{
+			name:     "load balancer IP answers",
+			provider: config.ProviderK3d,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				p.dial[c.Network.LoadBalancerIP+":443"] = nil
+			},
+			want: map[string]string{"load-balancer-ip": StatusFail},
+		},
+		{
+			name:     "load balancer IP refuses",
+			provider: config.ProviderK3d,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				p.dial[c.Network.LoadBalancerIP+":80"] = refused
+			},
+			want: map[string]string{"load-balancer-ip": StatusFail},
+		},
+		{
+			name:     "load balancer IP of the running cluster",
+			provider: config.ProviderK3d,
+			running:  true,
+			offline:  true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				p.dial[c.Network.LoadBalancerIP+":80"] = nil
+			},
+			want: map[string]string{"load-balancer-ip": StatusWarn},
+		},
+		{
+			name:       "existing cluster reachable",
+			provider:   config.ProviderExisting,
+			kubeconfig: kubeconfigWithContext,
+			running:    true,
+			offline:    true,
+			want:       map[string]string{"prerequisites": StatusPass, "kube-context": StatusPass, "load-balancer-ip": StatusPass},
+			absent:     []string{"kubectl", "disk", "docker-daemon", "port-80"},
+		},
+		{
+			name:       "existing cluster down",
+			provider:   config.ProviderExisting,
+			kubeconfig: kubeconfigWithContext,
+			offline:    true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				r.Missing("kubectl")
+			},
+			want: map[string]string{"prerequisites": StatusFail, "kube-context": StatusFail},
+		},
+		{
+			name:       "existing cluster unknown context",
+			provider:   config.ProviderExisting,
+			kubeconfig: kubeconfigWithContext,
+			running:    true,
+			offline:    true,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				c.Cluster.Context = "office"
+			},
+			want: map[string]string{"kube-context": StatusFail},
+		},
+		{
+			name:     "dns resolves",
+			provider: config.ProviderK3d,
+			want:     map[string]string{"dns": StatusPass},
+		},
+		{
+			name:     "dns fails",
+			provider: config.ProviderK3d,
+			script: func(c *config.Config, r *common.FakeRunner, p *fakeProbe) {
+				p.unresolved = []string{"charts.external-secrets.io"}
+			},
+			want: map[string]string{"dns": StatusFail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("KUBECONFIG", filepath.Join(home, "missing"))
+			if tt.kubeconfig != "" {
+				path := filepath.Join(home, "kubeconfig")
+				if err := os.WriteFile(path, []byte(tt.kubeconfig), 0600); err != nil {
+					t.Fatal(err)
+				}
+				t.Setenv("KUBECONFIG", path)
+			}
+
+			c := config.Default()
+			c.Cluster.Provider = tt.provider
+			runner := common.NewFakeRunner()
+			p := healthyProbe()
+			p.dial = map[string]error{}
+			if tt.script != nil {
+				tt.script(c, runner, p)
+			}
+			runner.On("df", dfOutput(400))
+
+			clientset := fake.NewClientset()
+			if tt.running {
+				clientset = fake.NewClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "server-0"}})
+			}
+			previous := common.CurrentRunner()
+			common.SetRunner(runner)
+			kube.SetClient(&kube.Client{Clientset: clientset})
+			SetProbe(p)
+			t.Cleanup(func() {
+				common.SetRunner(previous)
+				kube.SetClient(nil)
+				SetProbe(nil)
+			})
+
+			provider, err := cluster.New(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			results, _ := Run(runner, c, provider, tt.offline)
+
+			got := map[string]Result{}
+			for _, result := range results {
+				got[result.Check] = result
+			}
+			for check, status := range tt.want {
+				result, ok := got[check]
+				if !ok {
+					t.Errorf("check %s did not run", check)
+				} else if result.Status != status {
+					t.Errorf("check %s is %s, want %s: %s", check, result.Status, status, result.Message)
+				}
+			}
+			for _, check := range tt.absent {
+				if _, ok := got[check]; ok {
+					t.Errorf("check %s ran for the %s provider", check, tt.provider)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package doctor
+
+import (
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// clusterProbeTimeout bounds the check for an already running cluster
+const clusterProbeTimeout = 10 * time.Second
+
+// host probes the machine the checks run on
+type host struct {
+	runner   common.Runner
+	cfg      *config.Config
+	provider cluster.Provider
+	probe    Probe
+
+	// running caches clusterRunning, nil until probed
+	running *bool
+}
+
+// clusterRunning reports whether the provider's cluster answers, in which
+// case addresses and ports it holds are expected to be in use
+func (h *host) clusterRunning() bool {
+	if h.running != nil {
+		return *h.running
+	}
+
+	running := false
+	if client, err := kube.Get(); err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), clusterProbeTimeout)
+		_, err = client.FirstNode(ctx)
+		cancel()
+		running = err == nil
+	}
+	h.running = &running
+	return running
+}
+
+// freeDisk returns the space available to the user on the volume holding dir
+func (h *host) freeDisk(dir string) (uint64, error) {
+	// POSIX output keeps every volume on one line: filesystem, size, used, available, ...
+	output, err := h.runner.RunOutput("df", "-Pk", dir)
+	if err != nil {
+		return 0, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected df output")
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected df output")
+	}
+	kib, err := strconv.ParseUint(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected df output: %v", err)
+	}
+	return kib << 10, nil
+}
+
+func isConnectionRefused(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,175 @@
+package doctor
+
+import (
+	"austinhome/internal/logic/common"
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Probe reads the resources and the network of the machine the checks run
+// on. Commands like df and docker go through the runner instead.
+type Probe interface {
+	// NumCPU returns the number of CPUs of the host
+	NumCPU() int
+	// Memory returns the total and the available memory of the host in bytes
+	Memory() (total, available uint64, err error)
+	// Dial opens a TCP connection to address and closes it right away
+	Dial(address string) error
+	// LookupHost resolves a host name
+	LookupHost(ctx context.Context, name string) error
+	// Interfaces lists the network interfaces of the host
+	Interfaces() ([]Interface, error)
+}
+
+// Interface is a network interface of the host
+type Interface struct {
+	Name     string
+	Up       bool
+	Loopback bool
+	// Subnet is the first IPv4 network of the interface, nil if it has none
+	Subnet *net.IPNet
+	// Addrs are every address of the interface
+	Addrs []net.IP
+}
+
+// probe replaces the SystemProbe of Run when set
+var probe Probe
+
+// SetProbe replaces the probe used by Run, nil restores the SystemProbe
+func SetProbe(p Probe) {
+	probe = p
+}
+
+// CurrentProbe returns the probe set with SetProbe, nil for the SystemProbe
+func CurrentProbe() Probe {
+	return probe
+}
+
+// SystemProbe is the default Probe which reads the real host. Runner reads
+// the memory statistics on macOS.
+type SystemProbe struct {
+	Runner common.Runner
+}
+
+func (SystemProbe) NumCPU() int {
+	return runtime.NumCPU()
+}
+
+func (s SystemProbe) Memory() (uint64, uint64, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return s.darwinMemory()
+	case "linux":
+		return linuxMemory()
+	default:
+		return 0, 0, fmt.Errorf("not supported on %s", runtime.GOOS)
+	}
+}
+
+func (SystemProbe) Dial(address string) error {
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func (SystemProbe) LookupHost(ctx context.Context, name string) error {
+	_, err := net.DefaultResolver.LookupHost(ctx, name)
+	return err
+}
+
+func (SystemProbe) Interfaces() ([]Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Interface
+	for _, iface := range ifaces {
+		i := Interface{Name: iface.Name, Up: iface.Flags&net.FlagUp != 0, Loopback: iface.Flags&net.FlagLoopback != 0}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			i.Addrs = append(i.Addrs, ipNet.IP)
+			if i.Subnet == nil && ipNet.IP.To4() != nil {
+				i.Subnet = ipNet
+			}
+		}
+		result = append(result, i)
+	}
+	return result, nil
+}
+
+var pageSizePattern = regexp.MustCompile(`page size of (\d+) bytes`)
+
+// darwinMemory counts free, inactive and speculative pages as available, like Activity Monitor
+func (s SystemProbe) darwinMemory() (uint64, uint64, error) {
+	output, err := s.Runner.RunOutput("sysctl", "-n", "hw.memsize")
+	if err != nil {
+		return 0, 0, err
+	}
+	total, err := strconv.ParseUint(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("unexpected hw.memsize %q", strings.TrimSpace(output))
+	}
+
+	output, err = s.Runner.RunOutput("vm_stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	match := pageSizePattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, 0, fmt.Errorf("unexpected vm_stat output")
+	}
+	pageSize, _ := strconv.ParseUint(match[1], 10, 64)
+
+	var pages uint64
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Pages free", "Pages inactive", "Pages speculative":
+			count, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(value), "."), 10, 64)
+			pages += count
+		}
+	}
+	return total, pages * pageSize, nil
+}
+
+func linuxMemory() (uint64, uint64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 {
+			kib, _ := strconv.ParseUint(fields[1], 10, 64)
+			values[strings.TrimSuffix(fields[0], ":")] = kib << 10
+		}
+	}
+	if values["MemTotal"] == 0 {
+		return 0, 0, fmt.Errorf("MemTotal missing from /proc/meminfo")
+	}
+	return values["MemTotal"], values["MemAvailable"], nil
+}
@@ -0,0 +1,167 @@
+package events
+
+import (
+	"austinhome/internal/logic/redact"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event types emitted with --output json
+const (
+	TypeStepStarted  = "step_started"
+	TypeStepFinished = "step_finished"
+	TypeCommand      = "command"
+	TypeWarning      = "warning"
+	TypeError        = "error"
+	TypeComponent    = "component_status"
+	TypeSummary      = "summary"
+	TypeInventory    = "inventory"
+	TypeCheck        = "doctor_check"
+)
+
+// Step statuses reported in step_finished events and the summary
+const (
+	StatusSucceeded = "succeeded"
+	StatusWarning   = "warning"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// Event is a single JSON line written to the event stream
+type Event struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	Step       string    `json:"step,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	Command    string    `json:"command,omitempty"`
+	DurationMS int64     `json:"durationMs,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Data       any       `json:"data,omitempty"`
+}
+
+// StepResult is the outcome of a step as reported in the summary
+type StepResult struct {
+	Step       string `json:"step"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"durationMs"`
+	Message    string `json:"message,omitempty"`
+}
+
+var (
+	mu      sync.Mutex
+	encoder *json.Encoder
+	steps   []StepResult
+
+	// current is the running step, warnings collects the warnings raised during it
+	current  string
+	warnings []string
+)
+
+// Enable starts writing events as JSON lines to w
+func Enable(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	encoder = json.NewEncoder(w)
+}
+
+// Enabled reports whether events are being written
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return encoder != nil
+}
+
+// Emit writes e to the event stream, doing nothing when events are disabled
+func Emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if encoder == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Command, e.Message, e.Error = redact.String(e.Command), redact.String(e.Message), redact.String(e.Error)
+	encoder.Encode(e)
+}
+
+func StepStarted(step string) {
+	mu.Lock()
+	current, warnings = step, nil
+	mu.Unlock()
+
+	Emit(Event{Type: TypeStepStarted, Step: step})
+}
+
+// StepFinished emits the end of a step and records it for the summary. A
+// succeeded step that raised warnings is recorded as StatusWarning.
+func StepFinished(step, status string, duration time.Duration, message string) {
+	mu.Lock()
+	if step == current {
+		if status == StatusSucceeded && len(warnings) > 0 {
+			status = StatusWarning
+			if message == "" {
+				message = strings.Join(warnings, "; ")
+			}
+		}
+		current, warnings = "", nil
+	}
+	steps = append(steps, StepResult{Step: step, Status: status, DurationMS: duration.Milliseconds(), Message: message})
+	mu.Unlock()
+
+	Emit(Event{Type: TypeStepFinished, Step: step, Status: status, DurationMS: duration.Milliseconds(), Message: message})
+}
+
+func CommandExecuted(command string, duration time.Duration, err error) {
+	e := Event{Type: TypeCommand, Command: command, Status: StatusSucceeded, DurationMS: duration.Milliseconds()}
+	if err != nil {
+		e.Status, e.Error = StatusFailed, err.Error()
+	}
+	Emit(e)
+}
+
+// Warning emits a warning, attributing it to the running step if any
+func Warning(message string) {
+	mu.Lock()
+	step := current
+	if step != "" {
+		warnings = append(warnings, message)
+	}
+	mu.Unlock()
+
+	Emit(Event{Type: TypeWarning, Step: step, Message: message})
+}
+
+func Error(err error) {
+	Emit(Event{Type: TypeError, Error: err.Error()})
+}
+
+// Steps returns every step recorded so far, in order
+func Steps() []StepResult {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return append([]StepResult(nil), steps...)
+}
+
+// Summary emits the final object for command, including every recorded step
+// and any command specific data
+func Summary(command string, started time.Time, err error, data any) {
+	summary := map[string]any{"steps": Steps()}
+	if data != nil {
+		summary["result"] = data
+	}
+
+	e := Event{Type: TypeSummary, Command: command, Status: StatusSucceeded, DurationMS: time.Since(started).Milliseconds(), Data: summary}
+	if err != nil {
+		e.Status, e.Error = StatusFailed, err.Error()
+	}
+	Emit(e)
+}
@@ -1,19 +1,18 @@
 package install
 
 import (
+	"austinhome/internal/logic/charts"
 	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
 	"fmt"
 	"time"
 )
 
 const (
-	argoCDVersion     = "8.5.8"
-	argoCDRepoName    = "argo"
-	argoCDRepoURL     = "https://argoproj.github.io/argo-helm"
 	argoCDNamespace   = "argo-project"
 	argoCDMaxWaitTime = 3 * time.Minute
-	oauthSecretURL    = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-argocd/resources/oauth-secret.yaml"
-	argoCDValuesURL   = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-argocd/values.yaml"
 )
 
 func InstallArgoCD() error {
@@ -27,14 +26,6 @@ func InstallArgoCD() error {
 		return err
 	}
 
-	if err := addArgoCDRepo(); err != nil {
-		return err
-	}
-
-	if err := updateHelmRepoForArgoCD(); err != nil {
-		return err
-	}
-
 	if err := installArgoCDChart(); err != nil {
 		return err
 	}
@@ -49,61 +40,51 @@ func InstallArgoCD() error {
 
 func createArgoCDNamespace() error {
 	fmt.Println("📋 Creating ArgoCD namespace...")
-	// Using apply with a simple namespace manifest approach
-	err := common.RunCommand("kubectl", "create", "namespace", argoCDNamespace)
+	if common.IsDryRun() {
+		fmt.Printf("[dry-run] Would create namespace %s\n", argoCDNamespace)
+		return nil
+	}
+
+	client, err := kube.Get()
 	if err != nil {
-		// Namespace might already exist, check if it exists
-		checkErr := common.RunCommand("kubectl", "get", "namespace", argoCDNamespace)
-		if checkErr != nil {
-			return err // Return original error if namespace doesn't exist
-		}
-		fmt.Printf("Namespace %s already exists, continuing...\n", argoCDNamespace)
+		return err
 	}
-	return nil
+	return client.EnsureNamespace(context.Background(), argoCDNamespace)
 }
 
 func applyOAuthSecret() error {
 	fmt.Println("🔐 Applying OAuth secret...")
-	return common.RunCommand("kubectl", "apply", "-f", oauthSecretURL)
-}
-
-func addArgoCDRepo() error {
-	fmt.Println("📦 Adding ArgoCD Helm repository...")
-	return common.RunCommand("helm", "repo", "add", argoCDRepoName, argoCDRepoURL)
-}
-
-func updateHelmRepoForArgoCD() error {
-	fmt.Println("🔄 Updating Helm repositories...")
-	return common.RunCommand("helm", "repo", "update")
+	return applyManifest(cfg.Components.ArgoCD.OAuthSecretURL)
 }
 
 func installArgoCDChart() error {
 	fmt.Println("🚀 Installing ArgoCD chart...")
-	return common.RunCommand("helm", "upgrade", "--install", "argocd",
-		"argo/argo-cd",
-		"--namespace", argoCDNamespace,
-		"--create-namespace",
-		"--values", argoCDValuesURL,
-		"--version", argoCDVersion)
+	chart := cfg.Components.ArgoCD
+	return installChart(charts.Release{
+		Name:       "argocd",
+		Namespace:  argoCDNamespace,
+		Chart:      config.ArgoCDChart,
+		RepoURL:    chart.RepoURL,
+		Version:    chart.Version,
+		ValuesURLs: []string{chart.ValuesURL},
+		Set:        chart.SetValues(),
+	})
+}
+
+func checkArgoCD() ComponentStatus {
+	status := ComponentStatus{Component: "ArgoCD"}
+	checkHelmRelease(&status, "argocd", "argo-cd", argoCDNamespace)
+	checkPods(&status, argoCDNamespace, "app.kubernetes.io/part-of=argocd", false)
+	checkResource(&status, argoCDNamespace, "service", "argocd-server")
+	return status
 }
 
 func verifyArgoCDInstallation() error {
 	fmt.Println("🔍 Verifying ArgoCD installation...")
+	return reportStatus(checkArgoCD())
+}
 
-	fmt.Println("\n📋 ArgoCD pods status:")
-	if err := common.RunCommand("kubectl", "get", "pods", "-n", argoCDNamespace); err != nil {
-		return err
-	}
-
-	fmt.Println("\n🌐 ArgoCD service status:")
-	if err := common.RunCommand("kubectl", "get", "service", "-n", argoCDNamespace); err != nil {
-		fmt.Printf("Warning: failed to get ArgoCD service: %v\n", err)
-	}
-
-	fmt.Println("\n🚀 ArgoCD application status:")
-	if err := common.RunCommand("kubectl", "get", "application", "-n", argoCDNamespace); err != nil {
-		fmt.Printf("Info: No applications deployed yet\n")
-	}
-
-	return nil
+func UninstallArgoCD() error {
+	fmt.Println("🗑️ Removing ArgoCD...")
+	return uninstallHelmRelease("argocd", argoCDNamespace)
 }
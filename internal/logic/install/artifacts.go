@@ -0,0 +1,200 @@
+package install
+
+import (
+	"austinhome/internal/logic/charts"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"austinhome/internal/logic/lock"
+	"austinhome/internal/logic/manifest"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// artifacts maps every remote manifest and values URL to a local copy that
+// has been verified against the lock file
+var artifacts map[string]string
+
+// lockfile holds the expected digests of the running installation
+var lockfile *lock.Lock
+
+// lockPath is the lock file loaded on first use when removing components
+var lockPath string
+
+// UseLockFile makes component removal verify the manifests it deletes against
+// the lock file at path, loaded when the first manifest is needed
+func UseLockFile(path string) {
+	lockfile, lockPath = nil, path
+}
+
+// prepareArtifacts fetches the provider's scripts and every manifest and values
+// file of components, or takes them from the bundle, and verifies them against
+// the lock file before anything is applied
+func prepareArtifacts(components []Component) error {
+	artifacts = map[string]string{}
+
+	urls := cfg.ClusterURLs()
+	for _, component := range components {
+		if component.Artifacts == nil {
+			continue
+		}
+		for _, url := range component.Artifacts() {
+			if !slices.Contains(urls, url) {
+				urls = append(urls, url)
+			}
+		}
+	}
+
+	if common.IsDryRun() {
+		fmt.Printf("[dry-run] Would fetch %d manifest(s) and verify them against the lock file\n", len(urls))
+		return nil
+	}
+
+	dir, err := artifactsDir()
+	if err != nil {
+		return err
+	}
+	_, statErr := os.Stat(dir)
+
+	fmt.Println("🔏 Verifying manifests against the lock file...")
+	for _, url := range urls {
+		if _, err := storeArtifact(url); err != nil {
+			return err
+		}
+	}
+
+	// Recorded by the install only, uninstall consumes the manifest
+	if errors.Is(statErr, os.ErrNotExist) {
+		if err := manifest.AddDirectory(dir); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ %d manifest(s) verified\n", len(artifacts))
+	return nil
+}
+
+// storeArtifact fetches url, verifies it against the lock file and keeps a
+// local copy under ~/.austinhome/artifacts
+func storeArtifact(url string) (string, error) {
+	// Uninstall runs without a prior install loading the lock file
+	if lockfile == nil {
+		if lockPath == "" {
+			return "", fmt.Errorf("cannot verify %s without a lock file", url)
+		}
+		l, err := lock.Load(lockPath)
+		if err != nil {
+			return "", err
+		}
+		lockfile = l
+	}
+
+	dir, err := artifactsDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+
+	data, err := readArtifact(url)
+	if err != nil {
+		return "", err
+	}
+	if err := lockfile.VerifyFile(url, data); err != nil {
+		return "", err
+	}
+
+	local := filepath.Join(dir, lock.Digest(data)[:16]+"-"+path.Base(url))
+	if err := os.WriteFile(local, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to store %s: %v", url, err)
+	}
+	if artifacts == nil {
+		artifacts = map[string]string{}
+	}
+	artifacts[url] = local
+	return local, nil
+}
+
+// artifactsDir holds the verified copies, ~/.austinhome/artifacts
+func artifactsDir() (string, error) {
+	appDir, err := common.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(appDir, "artifacts"), nil
+}
+
+func readArtifact(url string) ([]byte, error) {
+	if offline != nil {
+		local, ok := offline.File(url)
+		if !ok {
+			return nil, fmt.Errorf("%s is missing from the bundle", url)
+		}
+		return os.ReadFile(local)
+	}
+	return lock.Fetch(url)
+}
+
+// applyManifest server-side applies the manifest at url. Unlike client-side
+// apply it converges on re-runs without the last-applied annotation, which
+// large CRDs like cert-manager's overflow.
+func applyManifest(url string) error {
+	local, err := artifact(url)
+	if err != nil {
+		return err
+	}
+	return common.RunCommand("kubectl", "apply", "--server-side", "--force-conflicts", "--field-manager", kube.FieldManager, "-f", local)
+}
+
+// deleteManifest deletes everything the verified manifest at url declares
+func deleteManifest(url string) error {
+	local, err := artifact(url)
+	if err != nil {
+		return err
+	}
+	return common.RunCommand("kubectl", "delete", "-f", local, "--ignore-not-found")
+}
+
+// artifact returns the verified local copy of a remote manifest or values
+// file, fetching it first when the install did not, e.g. for uninstall. A dry
+// run returns the URL itself since nothing is fetched or applied.
+func artifact(url string) (string, error) {
+	if local, ok := artifacts[url]; ok {
+		return local, nil
+	}
+	if common.IsDryRun() {
+		return url, nil
+	}
+	return storeArtifact(url)
+}
+
+// chartArchive returns a chart archive, from the bundle or downloaded, after
+// verifying it against the lock file
+func chartArchive(ref config.ChartRef) (string, error) {
+	var (
+		chartPath string
+		err       error
+	)
+	if offline != nil {
+		var ok bool
+		if chartPath, ok = offline.Chart(ref); !ok {
+			return "", fmt.Errorf("chart %s is missing from the bundle", ref)
+		}
+	} else if chartPath, err = charts.Pull(ref); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(chartPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read chart %s: %v", ref, err)
+	}
+	if err := lockfile.VerifyChart(ref, data); err != nil {
+		return "", err
+	}
+	return chartPath, nil
+}
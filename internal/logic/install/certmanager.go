@@ -7,11 +7,8 @@ import (
 )
 
 const (
-	certManagerVersion     = "1.18.2"
 	certManagerNamespace   = "cert-manager"
 	certManagerMaxWaitTime = 3 * time.Minute
-	route53SecretURL       = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-cert-manager/resources/route53-secret.yaml"
-	clusterIssuerURL       = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-cert-manager/resources/cluster-issuer.yaml"
 )
 
 func InstallCertManager() error {
@@ -39,37 +36,39 @@ func InstallCertManager() error {
 
 func applyCertManagerManifests() error {
 	fmt.Println("📦 Applying Cert-Manager manifests...")
-	manifestURL := fmt.Sprintf("https://github.com/cert-manager/cert-manager/releases/download/v%s/cert-manager.yaml", certManagerVersion)
-	return common.RunCommand("kubectl", "apply", "-f", manifestURL)
+	return applyManifest(cfg.Components.CertManager.ManifestURL())
 }
 
 func applyRoute53Secret() error {
 	fmt.Println("🔑 Applying Route53 secret...")
-	return common.RunCommand("kubectl", "apply", "-f", route53SecretURL)
+	return applyManifest(cfg.Components.CertManager.Route53SecretURL)
 }
 
 func applyClusterIssuer() error {
 	fmt.Println("📋 Applying ClusterIssuer...")
-	return common.RunCommand("kubectl", "apply", "-f", clusterIssuerURL)
+	return applyManifest(cfg.Components.CertManager.ClusterIssuerURL)
+}
+
+func checkCertManager() ComponentStatus {
+	status := ComponentStatus{Component: "Cert-Manager"}
+	checkPods(&status, certManagerNamespace, "app.kubernetes.io/instance=cert-manager", true)
+	checkResource(&status, "", "clusterissuer", "")
+	return status
 }
 
 func verifyCertManagerInstallation() error {
 	fmt.Println("🔍 Verifying Cert-Manager installation...")
+	return reportStatus(checkCertManager())
+}
 
-	fmt.Println("\n📋 Cert-Manager pods status:")
-	if err := common.RunCommand("kubectl", "get", "pods", "-n", certManagerNamespace); err != nil {
-		return err
-	}
-
-	fmt.Println("\n🔒 ClusterIssuer status:")
-	if err := common.RunCommand("kubectl", "get", "clusterissuer"); err != nil {
-		fmt.Printf("Warning: failed to get ClusterIssuer: %v\n", err)
-	}
+func UninstallCertManager() error {
+	fmt.Println("🗑️ Removing Cert-Manager...")
 
-	fmt.Println("\n🔑 Route53 secret status:")
-	if err := common.RunCommand("kubectl", "get", "secret", "-n", certManagerNamespace); err != nil {
-		fmt.Printf("Warning: failed to get secrets: %v\n", err)
+	for _, manifestURL := range []string{cfg.Components.CertManager.ClusterIssuerURL, cfg.Components.CertManager.Route53SecretURL} {
+		if err := deleteManifest(manifestURL); err != nil {
+			common.Warnf("failed to delete %s: %v", manifestURL, err)
+		}
 	}
 
-	return nil
+	return deleteManifest(cfg.Components.CertManager.ManifestURL())
 }
@@ -0,0 +1,268 @@
+package install
+
+import (
+	"austinhome/internal/logic/config"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Component is an add-on installed on top of the cluster
+type Component struct {
+	Name string
+	// Dependencies must be installed before this component
+	Dependencies []string
+	Install      func() error
+	// Verify checks the installed component; failures are reported as warnings
+	Verify func() error
+	// Installed cheaply confirms the component is still in place when resuming
+	Installed func() error
+	// Uninstall removes the component from the cluster, nil if nothing lives in the cluster
+	Uninstall func() error
+	// Status reports the health of the installed component
+	Status func() ComponentStatus
+	// Artifacts lists the remote manifest and values files the component
+	// applies, verified against the lock file before the install starts
+	Artifacts func() []string
+}
+
+// registry returns every component in install order, with the secret store
+// credentials bound to the eso-secretstore component
+func registry(credentials map[string]string) []Component {
+	return []Component{
+		{
+			Name:      "metrics-server",
+			Install:   enableEssentialAddons,
+			Verify:    verifyMetricsServer,
+			Installed: verifyMetricsServer,
+			Uninstall: UninstallMetricsServer,
+			Status:    checkMetricsServer,
+			Artifacts: func() []string {
+				return []string{cfg.Components.MetricsServer.ManifestURL()}
+			},
+		},
+		{
+			Name:    "metallb",
+			Install: InstallMetalLB,
+			Verify:  verifyMetalLBInstallation,
+			Installed: func() error {
+				return resourceExists("ipaddresspool", "metallb-system", "")
+			},
+			Uninstall: UninstallMetalLB,
+			Status:    checkMetalLB,
+			Artifacts: func() []string {
+				metalLB := cfg.Components.MetalLB
+				return []string{metalLB.NamespaceURL, metalLB.ManifestURL(), metalLB.IPConfigURL}
+			},
+		},
+		{
+			Name:         "ingress-nginx",
+			Dependencies: []string{"metallb"},
+			Install:      installIngressNginxAndVerifyConnectivity,
+			Verify:       verifyIngressNginxInstallation,
+			Installed: func() error {
+				ip, err := currentIngressIP()
+				if err != nil {
+					return err
+				}
+				return testIngressConnectivity(ip)
+			},
+			Uninstall: UninstallIngressNginx,
+			Status:    checkIngressNginx,
+		},
+		{
+			Name:    "external-secrets",
+			Install: InstallExternalSecretsOperator,
+			Verify:  verifyESOInstallation,
+			Installed: func() error {
+				return helmReleaseDeployed("external-secrets", esoNamespace)
+			},
+			Uninstall: UninstallExternalSecretsOperator,
+			Status:    checkESO,
+		},
+		{
+			Name:         "eso-secretstore",
+			Dependencies: []string{"external-secrets"},
+			Install: func() error {
+				return SetupESOSecretStore(credentials)
+			},
+			Verify:    verifyESOSecretStore,
+			Installed: secretStoreInstalled,
+			Uninstall: UninstallESOSecretStore,
+			Status:    checkESOSecretStore,
+			Artifacts: func() []string {
+				if url := store.ManifestURL(); url != "" {
+					return []string{url}
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "cert-manager",
+			Install: InstallCertManager,
+			Verify:  verifyCertManagerInstallation,
+			Installed: func() error {
+				return resourceExists("clusterissuer", "", "")
+			},
+			Uninstall: UninstallCertManager,
+			Status:    checkCertManager,
+			Artifacts: func() []string {
+				certManager := cfg.Components.CertManager
+				return []string{certManager.ManifestURL(), certManager.Route53SecretURL, certManager.ClusterIssuerURL}
+			},
+		},
+		{
+			Name:    "argocd",
+			Install: InstallArgoCD,
+			Verify:  verifyArgoCDInstallation,
+			Installed: func() error {
+				return helmReleaseDeployed("argocd", argoCDNamespace)
+			},
+			Uninstall: UninstallArgoCD,
+			Status:    checkArgoCD,
+			Artifacts: func() []string {
+				return []string{cfg.Components.ArgoCD.ValuesURL, cfg.Components.ArgoCD.OAuthSecretURL}
+			},
+		},
+	}
+}
+
+// ComponentNames returns the names of every registered component in install order
+func ComponentNames() []string {
+	var names []string
+	for _, component := range registry(nil) {
+		names = append(names, component.Name)
+	}
+	return names
+}
+
+// UninstallOrder returns the named components of the installation described by
+// c in reverse install order, so dependents are removed before what they need.
+// Removing a component that another installed component depends on is rejected
+// unless that one is removed as well.
+func UninstallOrder(c *config.Config, names []string) ([]Component, error) {
+	if err := use(c); err != nil {
+		return nil, err
+	}
+
+	all := registry(nil)
+	selected := map[string]bool{}
+	for _, name := range names {
+		if !includes(all, name) {
+			return nil, fmt.Errorf("unknown component %q, valid components are: %s", name, strings.Join(ComponentNames(), ", "))
+		}
+		selected[name] = true
+	}
+
+	for _, component := range all {
+		if selected[component.Name] {
+			continue
+		}
+		for _, dependency := range component.Dependencies {
+			if selected[dependency] && component.Installed() == nil {
+				return nil, fmt.Errorf("component %s depends on %s and is still installed, uninstall it as well", component.Name, dependency)
+			}
+		}
+	}
+
+	var result []Component
+	for i := len(all) - 1; i >= 0; i-- {
+		if selected[all[i].Name] {
+			result = append(result, all[i])
+		}
+	}
+	return result, nil
+}
+
+// resolveComponents selects components for --only / --skip. Dependencies of
+// --only components are added automatically, while skipping a dependency of a
+// selected component is rejected. The result keeps registry order.
+func resolveComponents(all []Component, only, skip []string) ([]Component, error) {
+	byName := map[string]Component{}
+	for _, component := range all {
+		byName[component.Name] = component
+	}
+
+	for _, name := range append(append([]string(nil), only...), skip...) {
+		if _, ok := byName[name]; !ok {
+			return nil, fmt.Errorf("unknown component %q, valid components are: %s", name, strings.Join(ComponentNames(), ", "))
+		}
+	}
+
+	skipped := map[string]bool{}
+	for _, name := range skip {
+		skipped[name] = true
+	}
+
+	selected := map[string]bool{}
+	if len(only) == 0 {
+		for _, component := range all {
+			selected[component.Name] = true
+		}
+	} else {
+		var add func(name, requiredBy string) error
+		add = func(name, requiredBy string) error {
+			if skipped[name] {
+				if requiredBy == "" {
+					return fmt.Errorf("component %s is both in --only and --skip", name)
+				}
+				return fmt.Errorf("component %s requires %s, which is skipped", requiredBy, name)
+			}
+			if selected[name] {
+				return nil
+			}
+			if requiredBy != "" {
+				fmt.Printf("ℹ️ Adding %s, required by %s\n", name, requiredBy)
+			}
+			selected[name] = true
+			for _, dependency := range byName[name].Dependencies {
+				if err := add(dependency, name); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for _, name := range only {
+			if err := add(name, ""); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for name := range skipped {
+		delete(selected, name)
+	}
+
+	var result []Component
+	for _, component := range all {
+		if !selected[component.Name] {
+			continue
+		}
+
+		var missing []string
+		for _, dependency := range component.Dependencies {
+			if !selected[dependency] {
+				missing = append(missing, dependency)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return nil, fmt.Errorf("component %s requires %s, which is skipped", component.Name, strings.Join(missing, ", "))
+		}
+
+		result = append(result, component)
+	}
+
+	return result, nil
+}
+
+// includes reports whether a component with name is part of components
+func includes(components []Component, name string) bool {
+	for _, component := range components {
+		if component.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -2,17 +2,17 @@ package install
 
 import (
 	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/kube"
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 )
 
-const (
-	gitlabClusterSecretStoreURL = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/app-clustersecrets/resources/gitlab-clustersecretstore.yaml"
-)
-
-func SetupESOSecretStore(gitlabPAT string) error {
-	fmt.Println("🔑 Setting up ESO SecretStore...")
+func SetupESOSecretStore(credentials map[string]string) error {
+	fmt.Printf("🔑 Setting up ESO SecretStore (%s)...\n", store.Name())
 
-	if err := createGitLabSecret(gitlabPAT); err != nil {
+	if err := createStoreSecret(credentials); err != nil {
 		return err
 	}
 
@@ -24,30 +24,132 @@ func SetupESOSecretStore(gitlabPAT string) error {
 	return nil
 }
 
-func createGitLabSecret(pat string) error {
-	fmt.Println("🔐 Creating GitLab ESO secret...")
-	return common.RunCommand("kubectl", "create", "secret", "generic", "gitlab-eso-secret",
-		"--namespace", esoNamespace,
-		"--from-literal=token="+pat)
+// createStoreSecret creates the credential Secret the ClusterSecretStore authenticates with
+func createStoreSecret(credentials map[string]string) error {
+	namespace, name := store.Secret()
+	if name == "" {
+		return nil
+	}
+
+	fmt.Printf("🔐 Creating %s ESO secret...\n", store.Name())
+	data, err := store.SecretData(credentials)
+	if err != nil {
+		return err
+	}
+
+	if common.IsDryRun() {
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Printf("[dry-run] Would create or update secret %s/%s with key(s) %s\n", namespace, name, strings.Join(keys, ", "))
+		return nil
+	}
+
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
+	if err := client.EnsureNamespace(context.Background(), namespace); err != nil {
+		return err
+	}
+	return client.ApplySecret(context.Background(), namespace, name, data)
 }
 
 func applyClusterSecretStore() error {
-	fmt.Println("📋 Applying GitLab ClusterSecretStore...")
-	return common.RunCommand("kubectl", "apply", "-f", gitlabClusterSecretStoreURL)
+	fmt.Printf("📋 Applying %s ClusterSecretStore...\n", store.Name())
+	if url := store.ManifestURL(); url != "" {
+		return applyManifest(url)
+	}
+
+	if common.IsDryRun() {
+		for _, obj := range store.Objects() {
+			fmt.Printf("[dry-run] Would apply %s %s\n", obj.GetKind(), objectName(obj.GetNamespace(), obj.GetName()))
+		}
+		return nil
+	}
+
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
+	for _, obj := range store.Objects() {
+		if err := client.Apply(context.Background(), obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretStoreInstalled cheaply confirms the credential Secret and store are still in place
+func secretStoreInstalled() error {
+	if namespace, name := store.Secret(); name != "" {
+		if err := resourceExists("secret", namespace, name); err != nil {
+			return err
+		}
+	}
+	return resourceExists("clustersecretstore", "", store.StoreName())
+}
+
+func checkESOSecretStore() ComponentStatus {
+	status := ComponentStatus{Component: "ESO SecretStore"}
+	if namespace, name := store.Secret(); name != "" {
+		checkResource(&status, namespace, "secret", name)
+	}
+	checkResource(&status, "", "clustersecretstore", store.StoreName())
+	return status
 }
 
 func verifyESOSecretStore() error {
 	fmt.Println("🔍 Verifying ESO SecretStore setup...")
+	return reportStatus(checkESOSecretStore())
+}
 
-	fmt.Println("\n🔑 GitLab secret status:")
-	if err := common.RunCommand("kubectl", "get", "secret", "gitlab-eso-secret", "-n", esoNamespace); err != nil {
+func UninstallESOSecretStore() error {
+	fmt.Println("🗑️ Removing ESO SecretStore...")
+
+	if url := store.ManifestURL(); url != "" {
+		if err := deleteManifest(url); err != nil {
+			return err
+		}
+	} else if err := deleteStoreObjects(); err != nil {
 		return err
 	}
 
-	fmt.Println("\n📋 ClusterSecretStore status:")
-	if err := common.RunCommand("kubectl", "get", "clustersecretstore"); err != nil {
-		fmt.Printf("Warning: failed to get ClusterSecretStore: %v\n", err)
+	namespace, name := store.Secret()
+	if name == "" {
+		return nil
 	}
+	return common.RunCommand("kubectl", "delete", "secret", name, "--namespace", namespace, "--ignore-not-found")
+}
 
+// deleteStoreObjects removes the generated store resources in reverse apply order
+func deleteStoreObjects() error {
+	objects := store.Objects()
+	if common.IsDryRun() {
+		for i := len(objects) - 1; i >= 0; i-- {
+			fmt.Printf("[dry-run] Would delete %s %s\n", objects[i].GetKind(), objectName(objects[i].GetNamespace(), objects[i].GetName()))
+		}
+		return nil
+	}
+
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
+	for i := len(objects) - 1; i >= 0; i-- {
+		if err := client.Delete(context.Background(), objects[i]); err != nil {
+			return err
+		}
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// objectName renders namespace/name, or just name for cluster scoped objects
+func objectName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
@@ -1,15 +1,14 @@
 package install
 
 import (
+	"austinhome/internal/logic/charts"
 	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
 	"fmt"
 	"time"
 )
 
 const (
-	esoVersion     = "0.20.2"
-	esoRepoName    = "external-secrets"
-	esoRepoURL     = "https://charts.external-secrets.io"
 	esoNamespace   = "external-secrets"
 	esoMaxWaitTime = 3 * time.Minute
 )
@@ -17,14 +16,6 @@ const (
 func InstallExternalSecretsOperator() error {
 	fmt.Println("🔐 Installing External Secrets Operator...")
 
-	if err := addESORepo(); err != nil {
-		return err
-	}
-
-	if err := updateHelmRepoForESO(); err != nil {
-		return err
-	}
-
 	if err := installESOChart(); err != nil {
 		return err
 	}
@@ -37,32 +28,33 @@ func InstallExternalSecretsOperator() error {
 	return nil
 }
 
-func addESORepo() error {
-	fmt.Println("📦 Adding External Secrets Helm repository...")
-	return common.RunCommand("helm", "repo", "add", esoRepoName, esoRepoURL)
-}
-
-func updateHelmRepoForESO() error {
-	fmt.Println("🔄 Updating Helm repositories...")
-	return common.RunCommand("helm", "repo", "update")
-}
-
 func installESOChart() error {
 	fmt.Println("🚀 Installing External Secrets chart...")
-	return common.RunCommand("helm", "install", "external-secrets",
-		"external-secrets/external-secrets",
-		"--namespace", esoNamespace,
-		"--version", esoVersion,
-		"--create-namespace")
+	chart := cfg.Components.ExternalSecrets.ChartConfig
+	return installChart(charts.Release{
+		Name:      "external-secrets",
+		Namespace: esoNamespace,
+		Chart:     config.ExternalSecretsChart,
+		RepoURL:   chart.RepoURL,
+		Version:   chart.Version,
+		Set:       chart.SetValues(),
+	})
+}
+
+func checkESO() ComponentStatus {
+	status := ComponentStatus{Component: "External Secrets"}
+	checkHelmRelease(&status, "external-secrets", "external-secrets", esoNamespace)
+	checkPods(&status, esoNamespace, "", false)
+	checkResource(&status, "", "crd", "clustersecretstores.external-secrets.io")
+	return status
 }
 
 func verifyESOInstallation() error {
 	fmt.Println("🔍 Verifying External Secrets Operator installation...")
+	return reportStatus(checkESO())
+}
 
-	fmt.Println("\n📋 External Secrets pods status:")
-	if err := common.RunCommand("kubectl", "get", "pods", "-n", esoNamespace); err != nil {
-		return err
-	}
-
-	return nil
+func UninstallExternalSecretsOperator() error {
+	fmt.Println("🗑️ Removing External Secrets Operator...")
+	return uninstallHelmRelease("external-secrets", esoNamespace)
 }
@@ -1,157 +1,219 @@
 package install
 
 import (
-	"bufio"
+	"austinhome/internal/logic/bundle"
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/lock"
+	"austinhome/internal/logic/secretstore"
 	"fmt"
 	"os"
 	"strings"
 )
 
-func Execute() error {
-	envLabel, err := getEnvironmentLabel()
-	if err != nil {
-		return err
-	}
+// cfg holds the configuration of the running installation
+var cfg = config.Default()
+
+// provider creates the cluster of the running installation
+var provider cluster.Provider
+
+// store bootstraps the ClusterSecretStore of the running installation
+var store secretstore.Provider
+
+// offline serves manifests and charts when installing from a bundle, nil otherwise
+var offline *bundle.Bundle
+
+// probe runs the preflight checks, which read the real host even in a dry run
+var probe common.Runner
+
+// Options controls how Execute performs the installation
+type Options struct {
+	// Config declares versions and settings, defaults to config.Default()
+	Config *config.Config
+	// Runner executes external commands, defaults to common.ExecRunner
+	Runner common.Runner
+	// DryRun prints every command, chart and manifest instead of applying it
+	DryRun bool
+
+	// EnvLabel is the node env label, prompted for when empty
+	EnvLabel string
+	// GitLabPATFile is a file containing the GitLab PAT
+	GitLabPATFile string
+	// GitLabPATEnv names an environment variable containing the GitLab PAT
+	GitLabPATEnv string
+
+	// KubeContext installs onto this context of an existing cluster instead of creating one
+	KubeContext string
+	// Kubeconfig installs onto an existing cluster from this kubeconfig file instead of creating one
+	Kubeconfig string
+
+	// Bundle installs every manifest and chart from this archive instead of downloading them
+	Bundle string
+	// LockFile pins the digest of every manifest and chart, defaults to lock.DefaultFileName
+	LockFile string
+
+	// Resume continues from the checkpoint left by a previous failed install
+	Resume bool
+	// RecreateCluster replaces a running cluster instead of reusing it
+	RecreateCluster bool
+	// NoRollback keeps the components of a failed install instead of removing them
+	NoRollback bool
+
+	// Only installs just these components and their dependencies
+	Only []string
+	// Skip leaves these components out
+	Skip []string
+}
 
-	gitlabPAT, err := getGitLabPAT()
-	if err != nil {
-		return err
+// Execute runs the full installation
+func Execute(opts Options) error {
+	runner := opts.Runner
+	if probe = opts.Runner; probe == nil {
+		probe = common.ExecRunner{}
 	}
-
-	// Validate prerequisites
-	if err := validatePrerequisites(); err != nil {
-		return err
+	if opts.DryRun {
+		runner = common.NewDryRunRunner(os.Stdout)
 	}
+	common.SetRunner(runner)
 
-	// Install Colima if needed
-	if err := installColimaIfNeeded(); err != nil {
-		return err
+	if opts.Config != nil {
+		cfg = opts.Config
 	}
 
-	// Setup Colima K3s cluster
-	if err := setupK3sCluster(); err != nil {
-		return err
+	if opts.KubeContext != "" || opts.Kubeconfig != "" {
+		cfg.Cluster.Provider = config.ProviderExisting
+		cfg.Cluster.Context, cfg.Cluster.Kubeconfig = opts.KubeContext, opts.Kubeconfig
 	}
 
-	// Enable essential addons and post-installation setup
-	if err := enableEssentialAddons(); err != nil {
+	if err := use(cfg); err != nil {
 		return err
 	}
+	cluster.Bind(provider)
+	cluster.UseArtifacts(artifact)
 
-	if err := setupPostInstallation(envLabel); err != nil {
-		return err
-	}
-
-	// Install Helm
-	if err := InstallHelm(); err != nil {
-		return err
-	}
-
-	if err := verifyHelmInstallation(); err != nil {
-		fmt.Printf("Warning: Helm verification failed: %v\n", err)
-	}
+	offline = nil
+	if opts.Bundle != "" {
+		b, err := bundle.Open(opts.Bundle)
+		if err != nil {
+			return err
+		}
+		defer b.Close()
 
-	// Install MetalLB for LoadBalancer support
-	if err := InstallMetalLB(); err != nil {
-		return err
+		if err := b.Covers(cfg); err != nil {
+			return err
+		}
+		offline = b
+		fmt.Printf("📦 Installing from bundle %s (created %s)\n", opts.Bundle, b.Index.CreatedAt.Format("2006-01-02 15:04"))
 	}
 
-	if err := verifyMetalLBInstallation(); err != nil {
-		fmt.Printf("Warning: MetalLB verification failed: %v\n", err)
-	}
-
-	// Install NGINX Ingress Controller
-	if err := InstallIngressNginx(); err != nil {
+	if err := loadLockFile(opts.LockFile); err != nil {
 		return err
 	}
 
-	if err := verifyIngressNginxInstallation(); err != nil {
-		fmt.Printf("Warning: Ingress Nginx verification failed: %v\n", err)
+	var err error
+	state := newInstallState()
+	if previous, err := loadInstallState(); err == nil && previous.Components != nil {
+		// Components installed by earlier runs are still on the cluster
+		state.Components = previous.Components
 	}
+	if opts.Resume {
+		if state, err = loadInstallState(); err != nil {
+			return err
+		}
 
-	// Critical: Test ingress connectivity, fail installation if this doesn't work
-	if err := VerifyIngressConnectivity(); err != nil {
-		fmt.Printf("❌ Critical: Ingress connectivity verification failed: %v\n", err)
-		fmt.Println("🛑 Installation aborted due to ingress connectivity issues")
-		return err
+		// Keep the label chosen by the interrupted run unless overridden
+		if opts.EnvLabel == "" {
+			opts.EnvLabel = state.EnvLabel
+		}
+		fmt.Printf("🔁 Resuming installation of %s (%d step(s) completed)\n", state.Cluster, len(state.Completed))
 	}
 
-	// Install External Secrets Operator
-	if err := InstallExternalSecretsOperator(); err != nil {
+	// Validate the selection before prompting for anything
+	components, err := resolveComponents(registry(nil), opts.Only, opts.Skip)
+	if err != nil {
 		return err
 	}
+	fmt.Printf("📦 Components: %s\n", componentList(components))
 
-	if err := verifyESOInstallation(); err != nil {
-		fmt.Printf("Warning: ESO verification failed: %v\n", err)
+	// The nodes of a cluster austinhome does not manage are never labeled
+	envLabel, credentials := "", placeholderCredentials()
+	if provider.Managed() {
+		envLabel = "<env-label>"
+	} else if opts.EnvLabel != "" {
+		common.Warnf("--env-label is ignored, the nodes of a cluster austinhome does not manage are not labeled")
 	}
+	if !common.IsDryRun() {
+		if provider.Managed() {
+			if envLabel, err = resolveEnvironmentLabel(opts); err != nil {
+				return err
+			}
+		}
 
-	if err := SetupESOSecretStore(gitlabPAT); err != nil {
-		return err
+		// Credentials are only needed to bootstrap the ESO SecretStore
+		if includes(components, "eso-secretstore") {
+			if credentials, err = resolveStoreCredentials(opts); err != nil {
+				return err
+			}
+		}
 	}
 
-	if err := verifyESOSecretStore(); err != nil {
-		fmt.Printf("Warning: ESO SecretStore verification failed: %v\n", err)
+	// Rebuild the selection with the resolved inputs bound
+	selected := components
+	components = nil
+	for _, component := range registry(credentials) {
+		if includes(selected, component.Name) {
+			components = append(components, component)
+		}
 	}
 
-	// Install Cert-Manager
-	if err := InstallCertManager(); err != nil {
+	if err := prepareArtifacts(components); err != nil {
 		return err
 	}
 
-	if err := verifyCertManagerInstallation(); err != nil {
-		fmt.Printf("Warning: Cert-Manager verification failed: %v\n", err)
-	}
-
-	// Install ArgoCD
-	if err := InstallArgoCD(); err != nil {
-		return err
-	}
+	state.Cluster, state.EnvLabel = cfg.Cluster.Name, envLabel
+	return runSteps(installSteps(envLabel, components, opts.RecreateCluster), state, opts.Resume, !opts.NoRollback)
+}
 
-	if err := verifyArgoCDInstallation(); err != nil {
-		fmt.Printf("Warning: ArgoCD verification failed: %v\n", err)
-	}
+// use makes c the configuration of the running operation
+func use(c *config.Config) error {
+	cfg = c
 
-	// Final verification
-	if err := verifyInstallation(); err != nil {
+	var err error
+	if provider, err = cluster.New(cfg); err != nil {
 		return err
 	}
-
-	return nil
+	store, err = secretstore.New(cfg, esoNamespace)
+	return err
 }
 
-func getEnvironmentLabel() (string, error) {
-	fmt.Print("Enter environment label for this cluster (e.g., dev, staging, prod): ")
-
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		return "", fmt.Errorf("failed to read input: %v", err)
+// loadLockFile loads the lock file and checks that it pins every artifact of cfg.
+// A dry run only warns about a missing or outdated lock file.
+func loadLockFile(path string) error {
+	if path == "" {
+		path = lock.DefaultFileName
 	}
 
-	envLabel := strings.TrimSpace(input)
-	if envLabel == "" {
-		envLabel = "dev" // default value
-		fmt.Println("Using default label: dev")
+	l, err := lock.Load(path)
+	if err == nil {
+		err = l.Covers(cfg)
 	}
-
-	fmt.Printf("✅ Environment label set to: %s\n", envLabel)
-	return envLabel, nil
-}
-
-func getGitLabPAT() (string, error) {
-	fmt.Print("Enter the GitLab PAT (Personal Access Token): ")
-
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
 	if err != nil {
-		return "", fmt.Errorf("failed to read GitLab PAT: %v", err)
+		if common.IsDryRun() {
+			fmt.Printf("[dry-run] Warning: %v\n", err)
+			return nil
+		}
+		return err
 	}
 
-	pat := strings.TrimSpace(input)
-	if pat == "" {
-		return "", fmt.Errorf("GitLab PAT cannot be empty")
-	}
+	lockfile = l
+	return nil
+}
 
-	fmt.Println("✅ GitLab PAT received")
-	return pat, nil
+func componentList(components []Component) string {
+	var names []string
+	for _, component := range components {
+		names = append(names, component.Name)
+	}
+	return strings.Join(names, ", ")
 }
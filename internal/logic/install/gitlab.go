@@ -0,0 +1,87 @@
+package install
+
+import (
+	"austinhome/internal/logic/common"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// gitlabPATPrefix is carried by every GitLab personal access token since 14.5
+	gitlabPATPrefix = "glpat-"
+	// gitlabPATMinLength is the prefix plus the 20 character token body
+	gitlabPATMinLength = len(gitlabPATPrefix) + 20
+
+	gitlabRequestTimeout = 15 * time.Second
+)
+
+// patShapeProblem describes why pat does not look like a GitLab PAT, empty if it does
+func patShapeProblem(pat string) string {
+	switch {
+	case !strings.HasPrefix(pat, gitlabPATPrefix):
+		return fmt.Sprintf("it does not start with %s", gitlabPATPrefix)
+	case len(pat) < gitlabPATMinLength:
+		return fmt.Sprintf("it is %d characters long, expected at least %d", len(pat), gitlabPATMinLength)
+	case strings.ContainsAny(pat, " \t"):
+		return "it contains whitespace"
+	}
+	return ""
+}
+
+// gitlabToken is the subset of GET /personal_access_tokens/self used for validation
+type gitlabToken struct {
+	Name      string   `json:"name"`
+	Active    bool     `json:"active"`
+	Revoked   bool     `json:"revoked"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
+
+// validateGitLabPAT asks the GitLab API about pat so a typo fails the install
+// before ESO is set up. An unreachable API is only a warning, as offline
+// installs cannot reach it either.
+func validateGitLabPAT(pat string) error {
+	baseURL := strings.TrimSuffix(cfg.Components.ExternalSecrets.GitLabURL, "/")
+	fmt.Printf("🔍 Validating GitLab PAT against %s...\n", baseURL)
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/api/v4/personal_access_tokens/self", nil)
+	if err != nil {
+		return fmt.Errorf("invalid GitLab URL %s: %v", baseURL, err)
+	}
+	req.Header.Set("PRIVATE-TOKEN", pat)
+
+	client := &http.Client{Timeout: gitlabRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		common.Warnf("Could not reach %s to validate the GitLab PAT: %v", baseURL, err)
+		return nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("GitLab rejected the PAT (401): check for typos, revocation or expiry")
+	case resp.StatusCode != http.StatusOK:
+		common.Warnf("Unexpected response from %s while validating the GitLab PAT: %s", baseURL, resp.Status)
+		return nil
+	}
+
+	var token gitlabToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		common.Warnf("Could not parse the GitLab PAT details: %v", err)
+		return nil
+	}
+	if token.Revoked || !token.Active {
+		return fmt.Errorf("GitLab PAT %q is not active", token.Name)
+	}
+
+	expires := "never expires"
+	if token.ExpiresAt != "" {
+		expires = "expires " + token.ExpiresAt
+	}
+	fmt.Printf("✅ GitLab PAT %q is valid (scopes: %s, %s)\n", token.Name, strings.Join(token.Scopes, ", "), expires)
+	return nil
+}
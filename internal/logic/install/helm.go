@@ -1,71 +1,75 @@
 package install
 
 import (
+	"austinhome/internal/logic/charts"
 	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
 	"fmt"
-	"os"
+	"strings"
 )
 
-func InstallHelm() error {
-	fmt.Println("⛵ Installing Helm...")
-
-	if err := downloadHelmInstaller(); err != nil {
-		return err
-	}
-
-	if err := makeInstallerExecutable(); err != nil {
-		return err
+// installChart installs or upgrades a chart release through the Helm SDK
+func installChart(release charts.Release) error {
+	// Copy before resolving, the caller's slice must keep the remote URLs
+	valuesFiles := make([]string, len(release.ValuesURLs))
+	for i, url := range release.ValuesURLs {
+		local, err := artifact(url)
+		if err != nil {
+			return err
+		}
+		valuesFiles[i] = local
 	}
+	release.ValuesURLs = valuesFiles
 
-	if err := runHelmInstaller(); err != nil {
-		return err
+	if common.IsDryRun() {
+		fmt.Printf("[dry-run] Would install chart %s %s from %s as %s in namespace %s\n",
+			release.Chart, release.Version, release.RepoURL, release.Name, release.Namespace)
+		for _, url := range release.ValuesURLs {
+			fmt.Printf("[dry-run]   values: %s\n", url)
+		}
+		if len(release.Set) > 0 {
+			fmt.Printf("[dry-run]   set: %s\n", strings.Join(release.Set, ", "))
+		}
+		return nil
 	}
 
-	if err := cleanupHelmInstaller(); err != nil {
+	chartPath, err := chartArchive(config.ChartRef{Name: release.Chart, RepoURL: release.RepoURL, Version: release.Version})
+	if err != nil {
 		return err
 	}
+	release.ChartPath = chartPath
 
-	return setupHelmForK3s()
-}
-
-func downloadHelmInstaller() error {
-	fmt.Println("📥 Downloading Helm installer...")
-	return common.RunCommand("curl", "-fsSL", "-o", "get_helm.sh",
-		"https://raw.githubusercontent.com/helm/helm/main/scripts/get-helm-3")
-}
-
-func makeInstallerExecutable() error {
-	fmt.Println("🔧 Making installer executable...")
-	return common.RunCommand("chmod", "700", "get_helm.sh")
+	return charts.Install(context.Background(), release)
 }
 
-func runHelmInstaller() error {
-	fmt.Println("🚀 Running Helm installer...")
-	return common.RunCommand("./get_helm.sh")
-}
-
-func cleanupHelmInstaller() error {
-	fmt.Println("🧹 Cleaning up installer...")
-	if err := os.Remove("get_helm.sh"); err != nil {
-		fmt.Printf("Warning: failed to remove installer: %v\n", err)
+// helmReleaseDeployed fails unless the release exists and is deployed
+func helmReleaseDeployed(release, namespace string) error {
+	info, err := charts.Status(release, namespace)
+	if err != nil {
+		return err
+	}
+	if info.Status != "deployed" {
+		return fmt.Errorf("helm release %s is %s", release, info.Status)
 	}
 	return nil
 }
 
-func setupHelmForK3s() error {
-	fmt.Println("🔧 Setting up Helm for K3s...")
+// uninstallHelmRelease removes a release and the namespace it was installed into
+func uninstallHelmRelease(release, namespace string) error {
+	if common.IsDryRun() {
+		fmt.Printf("[dry-run] Would uninstall release %s and delete namespace %s\n", release, namespace)
+		return nil
+	}
 
-	// Kubeconfig is already set up by configureKubectlAccess() in k3s.go
-	// Just verify Helm can connect to the cluster
-	if err := common.RunCommand("helm", "list", "--all-namespaces"); err != nil {
-		return fmt.Errorf("failed to connect Helm to K3s cluster: %v", err)
+	if err := charts.Uninstall(release, namespace); err != nil {
+		return err
 	}
 
-	fmt.Println("✅ Helm configured to use K3s cluster")
-	return nil
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
+	return client.DeleteNamespace(context.Background(), namespace)
 }
-
-func verifyHelmInstallation() error {
-	fmt.Println("✅ Verifying Helm installation...")
-	return common.RunCommand("helm", "version")
-}
\ No newline at end of file
@@ -1,32 +1,25 @@
 package install
 
 import (
+	"austinhome/internal/logic/charts"
 	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 )
 
-const (
-	ingressNginxVersion = "4.13.3"
-	ingressRepoName     = "ingress-nginx"
-	ingressRepoURL      = "https://kubernetes.github.io/ingress-nginx"
-	ingressNamespace    = "ingress-nginx"
-	loadBalancerIP      = "192.168.0.180"
-)
+const ingressNamespace = "ingress-nginx"
+
+// ingressClient requests the ingress through its LoadBalancer IP
+var ingressClient = &http.Client{Timeout: 10 * time.Second}
 
 func InstallIngressNginx() error {
 	fmt.Println("🌐 Installing Ingress Nginx...")
 
-	if err := addIngressRepo(); err != nil {
-		return err
-	}
-
-	if err := updateHelmRepo(); err != nil {
-		return err
-	}
-
 	if err := installIngressChart(); err != nil {
 		return err
 	}
@@ -35,88 +28,68 @@ func InstallIngressNginx() error {
 	return nil
 }
 
-func addIngressRepo() error {
-	fmt.Println("📦 Adding ingress-nginx Helm repository...")
-	return common.RunCommand("helm", "repo", "add", ingressRepoName, ingressRepoURL)
-}
-
-func updateHelmRepo() error {
-	fmt.Println("🔄 Updating Helm repositories...")
-	return common.RunCommand("helm", "repo", "update")
-}
-
 func installIngressChart() error {
 	fmt.Println("🚀 Installing ingress-nginx chart...")
-	return common.RunCommand("helm", "upgrade", "--install", "ingress-nginx",
-		"ingress-nginx/ingress-nginx",
-		"--namespace", ingressNamespace,
-		"--version", ingressNginxVersion,
-		"--set", "controller.kind=DaemonSet",
-		"--set", fmt.Sprintf("controller.service.loadBalancerIP=%s", loadBalancerIP),
-		"--set", "controller.progressDeadlineSeconds=null",
-		"--create-namespace")
+	chart := cfg.Components.IngressNginx
+	return installChart(charts.Release{
+		Name:      "ingress-nginx",
+		Namespace: ingressNamespace,
+		Chart:     config.IngressNginxChart,
+		RepoURL:   chart.RepoURL,
+		Version:   chart.Version,
+		Set: append([]string{fmt.Sprintf("controller.service.loadBalancerIP=%s", cfg.Network.LoadBalancerIP)},
+			chart.SetValues()...),
+	})
+}
+
+func checkIngressNginx() ComponentStatus {
+	status := ComponentStatus{Component: "Ingress Nginx"}
+	checkHelmRelease(&status, "ingress-nginx", "ingress-nginx", ingressNamespace)
+	checkPods(&status, ingressNamespace, "app.kubernetes.io/name=ingress-nginx", false)
+	checkResource(&status, ingressNamespace, "service", "ingress-nginx-controller")
+	checkResource(&status, "", "ingressclass", "nginx")
+	return status
 }
 
 func verifyIngressNginxInstallation() error {
 	fmt.Println("🔍 Verifying Ingress Nginx installation...")
-
-	fmt.Println("\n📋 Ingress Nginx pods status:")
-	if err := common.RunCommand("kubectl", "get", "pods", "-n", ingressNamespace); err != nil {
-		return err
-	}
-
-	fmt.Println("\n🌐 Ingress Nginx service status:")
-	if err := common.RunCommand("kubectl", "get", "service", "-n", ingressNamespace); err != nil {
-		fmt.Printf("Warning: failed to get ingress service: %v\n", err)
-	}
-
-	fmt.Println("\n⚙️ Ingress classes:")
-	if err := common.RunCommand("kubectl", "get", "ingressclass"); err != nil {
-		fmt.Printf("Warning: failed to get ingress classes: %v\n", err)
-	}
-
-	return nil
+	return reportStatus(checkIngressNginx())
 }
 
 func getIngressIP() (string, error) {
 	fmt.Println("🔍 Discovering Ingress IP address...")
 
-	// Wait for LoadBalancer to get an external IP
-	maxWaitTime := 5 * time.Minute
-	checkInterval := 10 * time.Second
-	startTime := time.Now()
+	client, err := kube.Get()
+	if err != nil {
+		return "", err
+	}
 
-	for time.Since(startTime) < maxWaitTime {
-		output, err := common.RunCommandOutput("kubectl", "get", "service", "ingress-nginx-controller", "-n", ingressNamespace, "-o", "jsonpath={.status.loadBalancer.ingress[0].ip}")
-		if err != nil {
-			return "", fmt.Errorf("failed to get ingress service info: %v", err)
-		}
+	// Wait for LoadBalancer to get an external IP
+	ip, err := client.WaitForLoadBalancerIP(context.Background(), ingressNamespace, "ingress-nginx-controller", 5*time.Minute)
+	if err != nil {
+		return "", err
+	}
 
-		ip := strings.TrimSpace(output)
-		if ip != "" && ip != "<nil>" {
-			fmt.Printf("✅ Found Ingress IP: %s\n", ip)
-			return ip, nil
-		}
+	fmt.Printf("✅ Found Ingress IP: %s\n", ip)
+	return ip, nil
+}
 
-		fmt.Printf("⏳ Waiting for LoadBalancer IP... (%v elapsed)\n", time.Since(startTime).Truncate(time.Second))
-		time.Sleep(checkInterval)
+// currentIngressIP returns the LoadBalancer IP assigned to the ingress controller, or "" if none yet
+func currentIngressIP() (string, error) {
+	client, err := kube.Get()
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("timeout: LoadBalancer IP not assigned after %v", maxWaitTime)
+	return client.LoadBalancerIP(context.Background(), ingressNamespace, "ingress-nginx-controller")
 }
 
 func testIngressConnectivity(ip string) error {
 	fmt.Printf("🧪 Testing Ingress connectivity at %s...\n", ip)
 
-	// Test HTTP connection to the ingress
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
 	testURL := fmt.Sprintf("http://%s", ip)
 	fmt.Printf("📡 Making HTTP request to %s\n", testURL)
 
-	resp, err := client.Get(testURL)
+	resp, err := ingressClient.Get(testURL)
 	if err != nil {
 		return fmt.Errorf("failed to connect to ingress at %s: %v", ip, err)
 	}
@@ -199,11 +172,16 @@ func performNetworkAnalysis(ip string) error {
 func VerifyIngressConnectivity() error {
 	fmt.Println("🌐 Verifying Ingress connectivity...")
 
+	if common.IsDryRun() {
+		fmt.Printf("[dry-run] Would test HTTP connectivity to the ingress LoadBalancer IP (%s)\n", cfg.Network.LoadBalancerIP)
+		return nil
+	}
+
 	// Wait for ingress controller pods to be ready
 	maxWaitTime := 3 * time.Minute
 	err := common.WaitForPodsReady(ingressNamespace, "app.kubernetes.io/name=ingress-nginx", maxWaitTime)
 	if err != nil {
-		fmt.Printf("⚠️ Warning: %v, proceeding anyway\n", err)
+		common.Warnf("%v, proceeding anyway", err)
 	}
 
 	// Get the ingress IP
@@ -226,4 +204,25 @@ func VerifyIngressConnectivity() error {
 
 	fmt.Println("✅ All Ingress connectivity tests passed!")
 	return nil
-}
\ No newline at end of file
+}
+
+// installIngressNginxAndVerifyConnectivity installs the chart and fails the
+// installation if the ingress is not reachable
+func installIngressNginxAndVerifyConnectivity() error {
+	if err := InstallIngressNginx(); err != nil {
+		return err
+	}
+
+	// Critical: fail installation if ingress is not reachable
+	if err := VerifyIngressConnectivity(); err != nil {
+		fmt.Printf("❌ Critical: Ingress connectivity verification failed: %v\n", err)
+		fmt.Println("🛑 Installation aborted due to ingress connectivity issues")
+		return err
+	}
+	return nil
+}
+
+func UninstallIngressNginx() error {
+	fmt.Println("🗑️ Removing Ingress Nginx...")
+	return uninstallHelmRelease("ingress-nginx", ingressNamespace)
+}
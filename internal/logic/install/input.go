@@ -0,0 +1,197 @@
+package install
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/redact"
+	"austinhome/internal/logic/secretstore"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Environment variables read when the matching flag is not given
+const (
+	EnvLabelVar  = "AUSTINHOME_ENV_LABEL"
+	GitLabPATVar = secretstore.GitLabPATVar
+)
+
+var labelValuePattern = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+
+func resolveEnvironmentLabel(opts Options) (string, error) {
+	envLabel := opts.EnvLabel
+	if envLabel == "" {
+		envLabel = os.Getenv(EnvLabelVar)
+	}
+
+	if envLabel == "" {
+		if common.StdinIsTerminal() {
+			return getEnvironmentLabel()
+		}
+		envLabel = "dev"
+		fmt.Printf("No --env-label or %s given and stdin is not a terminal, using default label: dev\n", EnvLabelVar)
+	}
+
+	if !labelValuePattern.MatchString(envLabel) {
+		return "", fmt.Errorf("invalid environment label %q: must be a valid Kubernetes label value", envLabel)
+	}
+
+	fmt.Printf("✅ Environment label set to: %s\n", envLabel)
+	return envLabel, nil
+}
+
+func resolveGitLabPAT(opts Options) (string, error) {
+	var pat, source string
+	switch {
+	case opts.GitLabPATFile != "":
+		data, err := os.ReadFile(opts.GitLabPATFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read GitLab PAT file: %v", err)
+		}
+		pat, source = strings.TrimSpace(string(data)), opts.GitLabPATFile
+	case opts.GitLabPATEnv != "":
+		pat, source = strings.TrimSpace(os.Getenv(opts.GitLabPATEnv)), "$"+opts.GitLabPATEnv
+	case os.Getenv(GitLabPATVar) != "":
+		pat, source = strings.TrimSpace(os.Getenv(GitLabPATVar)), "$"+GitLabPATVar
+	default:
+		if common.StdinIsTerminal() {
+			return getGitLabPAT()
+		}
+		return "", fmt.Errorf("GitLab PAT is required but stdin is not a terminal: pass --gitlab-pat-file, --gitlab-pat-env or set %s", GitLabPATVar)
+	}
+
+	if pat == "" {
+		return "", fmt.Errorf("GitLab PAT from %s is empty", source)
+	}
+	if problem := patShapeProblem(pat); problem != "" {
+		common.Warnf("GitLab PAT from %s does not look like a GitLab PAT: %s", source, problem)
+	}
+
+	fmt.Printf("✅ GitLab PAT read from %s\n", source)
+	return pat, nil
+}
+
+// resolveStoreCredentials resolves every credential of the secret store provider.
+// The GitLab PAT keeps its dedicated flags and is validated against the API.
+func resolveStoreCredentials(opts Options) (map[string]string, error) {
+	credentials := map[string]string{}
+	for _, credential := range store.Credentials() {
+		var value string
+		var err error
+		if store.Name() == config.SecretStoreGitLab {
+			value, err = resolveGitLabPAT(opts)
+		} else {
+			value, err = resolveCredential(credential)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Mask the value in every command line, warning and event from here on
+		redact.Register(value)
+		credentials[credential.Key] = value
+	}
+
+	if store.Name() == config.SecretStoreGitLab {
+		if err := validateGitLabPAT(credentials["token"]); err != nil {
+			return nil, err
+		}
+	}
+	return credentials, nil
+}
+
+// resolveCredential reads a credential from its environment variable, or prompts for it on a terminal
+func resolveCredential(credential secretstore.Credential) (string, error) {
+	if value := strings.TrimSpace(os.Getenv(credential.Env)); value != "" {
+		fmt.Printf("✅ %s read from $%s\n", credential.Prompt, credential.Env)
+		return value, nil
+	}
+
+	if !common.StdinIsTerminal() {
+		return "", fmt.Errorf("%s is required but stdin is not a terminal: set %s", credential.Prompt, credential.Env)
+	}
+
+	value, err := readSecret(fmt.Sprintf("Enter the %s: ", credential.Prompt))
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", credential.Prompt, err)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s cannot be empty", credential.Prompt)
+	}
+
+	fmt.Printf("✅ %s received\n", credential.Prompt)
+	return value, nil
+}
+
+// placeholderCredentials stands in for the store credentials when nothing is prompted for
+func placeholderCredentials() map[string]string {
+	credentials := map[string]string{}
+	for _, credential := range store.Credentials() {
+		credentials[credential.Key] = "<" + credential.Key + ">"
+	}
+	return credentials
+}
+
+func getEnvironmentLabel() (string, error) {
+	fmt.Print("Enter environment label for this cluster (e.g., dev, staging, prod): ")
+
+	input, err := common.ReadLine()
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %v", err)
+	}
+
+	envLabel := strings.TrimSpace(input)
+	if envLabel == "" {
+		envLabel = "dev" // default value
+		fmt.Println("Using default label: dev")
+	}
+
+	if !labelValuePattern.MatchString(envLabel) {
+		return "", fmt.Errorf("invalid environment label %q: must be a valid Kubernetes label value", envLabel)
+	}
+
+	fmt.Printf("✅ Environment label set to: %s\n", envLabel)
+	return envLabel, nil
+}
+
+// maxPATAttempts bounds how often a declined GitLab PAT is prompted for again
+const maxPATAttempts = 3
+
+func getGitLabPAT() (string, error) {
+	for attempt := 1; attempt <= maxPATAttempts; attempt++ {
+		pat, err := readSecret("Enter the GitLab PAT (Personal Access Token): ")
+		if err != nil {
+			return "", fmt.Errorf("failed to read GitLab PAT: %v", err)
+		}
+		if pat == "" {
+			return "", fmt.Errorf("GitLab PAT cannot be empty")
+		}
+
+		if problem := patShapeProblem(pat); problem != "" {
+			fmt.Printf("⚠️ This does not look like a GitLab PAT: %s\n", problem)
+			if !common.Confirm("Use it anyway?") {
+				continue
+			}
+		}
+
+		fmt.Println("✅ GitLab PAT received")
+		return pat, nil
+	}
+	return "", fmt.Errorf("no GitLab PAT accepted after %d attempts", maxPATAttempts)
+}
+
+// readSecret prompts for a value on the terminal without echoing it. Callers
+// read piped secrets from flags or environment variables instead.
+func readSecret(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	input, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(input)), nil
+}
@@ -6,12 +6,7 @@ import (
 	"time"
 )
 
-const (
-	metalLBVersion      = "0.15.2"
-	maxWaitTime         = 3 * time.Minute
-	metalLBNamespaceURL = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-metallb/resources/namespace.yaml"
-	metalLBIPConfigURL  = "https://raw.githubusercontent.com/BeaverHouse/cicd/refs/heads/main/charts/oss-metallb/resources/ipconfig.yaml"
-)
+const maxWaitTime = 3 * time.Minute
 
 func InstallMetalLB() error {
 	fmt.Println("🔩 Installing MetalLB...")
@@ -38,13 +33,12 @@ func InstallMetalLB() error {
 
 func applyNamespace() error {
 	fmt.Println("📋 Applying MetalLB namespace...")
-	return common.RunCommand("kubectl", "apply", "-f", metalLBNamespaceURL)
+	return applyManifest(cfg.Components.MetalLB.NamespaceURL)
 }
 
 func applyMetalLBManifests() error {
 	fmt.Println("📦 Applying MetalLB manifests...")
-	manifestURL := fmt.Sprintf("https://raw.githubusercontent.com/metallb/metallb/v%s/config/manifests/metallb-native.yaml", metalLBVersion)
-	return common.RunCommand("kubectl", "apply", "-f", manifestURL)
+	return applyManifest(cfg.Components.MetalLB.ManifestURL())
 }
 
 func waitForMetalLBPods() error {
@@ -53,21 +47,31 @@ func waitForMetalLBPods() error {
 
 func applyIPConfig() error {
 	fmt.Println("🌐 Applying MetalLB IP configuration...")
-	return common.RunCommand("kubectl", "apply", "-f", metalLBIPConfigURL)
+	return applyManifest(cfg.Components.MetalLB.IPConfigURL)
+}
+
+func checkMetalLB() ComponentStatus {
+	status := ComponentStatus{Component: "MetalLB"}
+	checkPods(&status, "metallb-system", "app=metallb", true)
+	checkResource(&status, "metallb-system", "ipaddresspool", "")
+	return status
 }
 
 func verifyMetalLBInstallation() error {
 	fmt.Println("🔍 Verifying MetalLB installation...")
+	return reportStatus(checkMetalLB())
+}
 
-	fmt.Println("\n📋 MetalLB pods status:")
-	if err := common.RunCommand("kubectl", "get", "pods", "-n", "metallb-system"); err != nil {
-		return err
+func UninstallMetalLB() error {
+	fmt.Println("🗑️ Removing MetalLB...")
+
+	if err := deleteManifest(cfg.Components.MetalLB.IPConfigURL); err != nil {
+		common.Warnf("failed to delete MetalLB IP configuration: %v", err)
 	}
 
-	fmt.Println("\n⚙️ MetalLB configuration:")
-	if err := common.RunCommand("kubectl", "get", "ipaddresspool", "-n", "metallb-system"); err != nil {
-		fmt.Printf("Warning: failed to get IP address pool: %v\n", err)
+	if err := deleteManifest(cfg.Components.MetalLB.ManifestURL()); err != nil {
+		return err
 	}
 
-	return nil
+	return deleteManifest(cfg.Components.MetalLB.NamespaceURL)
 }
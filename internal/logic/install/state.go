@@ -0,0 +1,176 @@
+package install
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"austinhome/internal/logic/manifest"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// unsafeFileChars are replaced in the state file name, context names may be ARNs
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// installState is the checkpoint persisted after every install step so that
+// `install --resume` can continue from the first step that did not complete
+type installState struct {
+	Cluster   string               `json:"cluster"`
+	EnvLabel  string               `json:"envLabel"`
+	Completed map[string]time.Time `json:"completed"`
+	// Components lists the components installed on the cluster, kept across
+	// runs. It is missing from the state of older versions.
+	Components []string  `json:"components"`
+	FailedStep string    `json:"failedStep,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func newInstallState() *installState {
+	return &installState{
+		Cluster:    cfg.Cluster.Name,
+		Completed:  map[string]time.Time{},
+		Components: []string{},
+	}
+}
+
+// stateFilePath keys the state by provider and kube context, so the checkpoint
+// of one cluster is never resumed against another
+func stateFilePath() (string, error) {
+	dir, err := common.AppDir()
+	if err != nil {
+		return "", err
+	}
+
+	path, contextName := provider.Kubeconfig()
+	if contextName == "" {
+		if contextName, err = kube.CurrentContext(path); err != nil {
+			return "", err
+		}
+	}
+	key := unsafeFileChars.ReplaceAllString(provider.Name()+"-"+contextName, "_")
+	return filepath.Join(dir, "state-"+key+".json"), nil
+}
+
+// loadInstallState reads the checkpoint left by a previous install
+func loadInstallState() (*installState, error) {
+	path, err := stateFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no previous install state found at %s, run install without --resume", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install state: %v", err)
+	}
+
+	state := newInstallState()
+	// Left nil when the state predates the component list
+	state.Components = nil
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse install state %s: %v", path, err)
+	}
+
+	if state.Cluster != cfg.Cluster.Name {
+		return nil, fmt.Errorf("install state at %s belongs to cluster %q, not %q", path, state.Cluster, cfg.Cluster.Name)
+	}
+	return state, nil
+}
+
+func (s *installState) isCompleted(step string) bool {
+	_, ok := s.Completed[step]
+	return ok
+}
+
+func (s *installState) markCompleted(step string) error {
+	s.Completed[step] = time.Now()
+	s.FailedStep, s.Error = "", ""
+	if includes(registry(nil), step) && !slices.Contains(s.Components, step) {
+		s.Components = append(s.Components, step)
+	}
+	return s.save()
+}
+
+func (s *installState) markFailed(step string, stepErr error) error {
+	delete(s.Completed, step)
+	s.FailedStep, s.Error = step, stepErr.Error()
+	return s.save()
+}
+
+// markRolledBack forgets steps that were undone after a failure
+func (s *installState) markRolledBack(steps []string) error {
+	for _, step := range steps {
+		delete(s.Completed, step)
+	}
+	s.Components = slices.DeleteFunc(s.Components, func(name string) bool {
+		return slices.Contains(steps, name)
+	})
+	return s.save()
+}
+
+// InstalledComponents returns the components recorded as installed on the
+// cluster of c, and false when no install state was recorded for it
+func InstalledComponents(c *config.Config) ([]string, bool) {
+	if err := use(c); err != nil {
+		return nil, false
+	}
+	state, err := loadInstallState()
+	if err != nil {
+		return nil, false
+	}
+	return state.Components, state.Components != nil
+}
+
+// ForgetComponents drops uninstalled components from the install state of c
+func ForgetComponents(c *config.Config, names []string) error {
+	if err := use(c); err != nil {
+		return err
+	}
+	state, err := loadInstallState()
+	if err != nil {
+		// Nothing recorded, nothing to forget
+		return nil
+	}
+	return state.markRolledBack(names)
+}
+
+func (s *installState) save() error {
+	// A dry run must not leave a checkpoint behind
+	if common.IsDryRun() {
+		return nil
+	}
+
+	path, err := stateFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	s.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, statErr := os.Stat(path)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write install state: %v", err)
+	}
+	// Recorded once so that a full uninstall removes the checkpoints as well
+	if errors.Is(statErr, os.ErrNotExist) {
+		return manifest.AddFile(path)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package install
+
+import (
+	"austinhome/internal/logic/charts"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/kube"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResourceCheck records whether a key resource of a component exists
+type ResourceCheck struct {
+	Kind    string `json:"kind"`
+	Name    string `json:"name,omitempty"`
+	Present bool   `json:"present"`
+}
+
+func (r ResourceCheck) String() string {
+	if r.Name == "" {
+		return r.Kind
+	}
+	return r.Kind + "/" + r.Name
+}
+
+// ComponentStatus is the health of a single managed component
+type ComponentStatus struct {
+	Component string          `json:"component"`
+	Version   string          `json:"version,omitempty"`
+	PodsReady int             `json:"podsReady"`
+	PodsTotal int             `json:"podsTotal"`
+	Resources []ResourceCheck `json:"resources"`
+	// Problems explains why the component is degraded, empty when healthy
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Healthy reports whether every pod is ready and every key resource is present
+func (s ComponentStatus) Healthy() bool {
+	return len(s.Problems) == 0
+}
+
+// CheckComponents collects the status of every component installed with c.
+// Components left out with --only or --skip are not checked, unless no
+// install state was recorded, e.g. for clusters installed by older versions.
+func CheckComponents(c *config.Config) ([]ComponentStatus, error) {
+	installed, recorded := InstalledComponents(c)
+	if err := use(c); err != nil {
+		return nil, err
+	}
+
+	var statuses []ComponentStatus
+	for _, component := range registry(nil) {
+		if recorded && !slices.Contains(installed, component.Name) {
+			continue
+		}
+		statuses = append(statuses, component.Status())
+	}
+	return statuses, nil
+}
+
+// reportStatus prints a component status and returns an error if it is degraded
+func reportStatus(status ComponentStatus) error {
+	version := status.Version
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Printf("📋 %s: version %s, pods ready %d/%d\n", status.Component, version, status.PodsReady, status.PodsTotal)
+
+	for _, resource := range status.Resources {
+		mark := "✅"
+		if !resource.Present {
+			mark = "❌"
+		}
+		fmt.Printf("   %s %s\n", mark, resource)
+	}
+
+	if !status.Healthy() {
+		return fmt.Errorf("%s is degraded: %s", status.Component, strings.Join(status.Problems, "; "))
+	}
+	return nil
+}
+
+// checkPods fills in pod readiness for the pods matching selector in namespace.
+// When useImageVersion is set the image tag of the first container is reported as the version.
+func checkPods(status *ComponentStatus, namespace, selector string, useImageVersion bool) {
+	client, err := kube.Get()
+	if err != nil {
+		status.Problems = append(status.Problems, err.Error())
+		return
+	}
+
+	pods, err := client.ListPods(context.Background(), namespace, selector)
+	if err != nil {
+		status.Problems = append(status.Problems, err.Error())
+		return
+	}
+
+	status.PodsTotal = len(pods)
+	for i := range pods {
+		if kube.PodReady(&pods[i]) {
+			status.PodsReady++
+		}
+	}
+
+	if useImageVersion && len(pods) > 0 && len(pods[0].Spec.Containers) > 0 {
+		image := pods[0].Spec.Containers[0].Image
+		if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+			status.Version = strings.TrimPrefix(image[i+1:], "v")
+		}
+	}
+
+	switch {
+	case status.PodsTotal == 0:
+		status.Problems = append(status.Problems, fmt.Sprintf("no pods found in %s", namespace))
+	case status.PodsReady < status.PodsTotal:
+		status.Problems = append(status.Problems, fmt.Sprintf("%d/%d pods ready in %s", status.PodsReady, status.PodsTotal, namespace))
+	}
+}
+
+// checkHelmRelease reports the chart version of a release and flags it if not deployed
+func checkHelmRelease(status *ComponentStatus, release, chart, namespace string) {
+	info, err := charts.Status(release, namespace)
+	if err != nil {
+		status.Problems = append(status.Problems, err.Error())
+		return
+	}
+
+	status.Version = info.Version
+	if info.Chart != chart {
+		status.Problems = append(status.Problems, fmt.Sprintf("helm release %s runs chart %s, expected %s", release, info.Chart, chart))
+	}
+	if info.Status != "deployed" {
+		status.Problems = append(status.Problems, fmt.Sprintf("helm release %s is %s", release, info.Status))
+	}
+}
+
+// checkResource records whether kind (optionally name) exists in namespace ("" for cluster scoped)
+func checkResource(status *ComponentStatus, namespace, kind, name string) {
+	check := ResourceCheck{Kind: kind, Name: name, Present: resourceExists(kind, namespace, name) == nil}
+	status.Resources = append(status.Resources, check)
+
+	if !check.Present {
+		status.Problems = append(status.Problems, fmt.Sprintf("%s missing", check))
+	}
+}
@@ -0,0 +1,215 @@
+package install
+
+import (
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/events"
+	"austinhome/internal/logic/kube"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// step is a named unit of the install pipeline whose completion is checkpointed
+type step struct {
+	name string
+	run  func() error
+	// verify cheaply confirms a completed step is still in place when resuming.
+	// Steps without verify are cheap enough to always run again.
+	verify func() error
+	// undo reverts the step when a later step fails, nil if nothing needs reverting
+	undo func() error
+}
+
+// clusterProbeTimeout bounds the check for an already running cluster
+const clusterProbeTimeout = 10 * time.Second
+
+func installSteps(envLabel string, components []Component, recreateCluster bool) []step {
+	steps := []step{{name: "prerequisites", run: validatePrerequisites}}
+	if provider.Managed() {
+		steps = append(steps, step{name: "tooling", run: provider.InstallTooling})
+	}
+
+	// Create only checks connectivity for clusters austinhome does not manage
+	steps = append(steps,
+		step{name: "cluster", run: func() error {
+			return createCluster(recreateCluster)
+		}, verify: func() error {
+			return common.RunCommand("kubectl", "get", "nodes")
+		}},
+		step{name: "post-installation", run: func() error {
+			return setupPostInstallation(envLabel)
+		}, verify: func() error {
+			if !provider.Managed() {
+				return nil
+			}
+			client, err := kube.Get()
+			if err != nil {
+				return err
+			}
+			return client.NodeWithLabelExists(context.Background(), "env", envLabel)
+		}},
+	)
+
+	for _, component := range components {
+		steps = append(steps, step{
+			name:   component.Name,
+			run:    withVerification(component.Name, component.Install, component.Verify),
+			verify: component.Installed,
+			undo:   component.Uninstall,
+		})
+	}
+
+	return append(steps, step{name: "final-verification", run: verifyInstallation})
+}
+
+// createCluster reuses a managed cluster that is already running so a second
+// install converges instead of wiping it, unless recreate asks for a fresh one
+func createCluster(recreate bool) error {
+	if !recreate && provider.Managed() {
+		if common.IsDryRun() {
+			fmt.Println("[dry-run] A running cluster would be reused, pass --recreate-cluster to replace it")
+		} else if err := clusterRunning(); err == nil {
+			fmt.Printf("♻️ Cluster %s is already running, reusing it (pass --recreate-cluster for a fresh one)\n", cfg.Cluster.Name)
+			return nil
+		}
+	}
+
+	if err := provider.Create(); err != nil {
+		return err
+	}
+	// The new cluster may have a new address and certificates, drop the old connection
+	kube.Configure(kube.Kubeconfig())
+	return nil
+}
+
+// clusterRunning fails unless the provider's cluster answers with at least one node
+func clusterRunning() error {
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clusterProbeTimeout)
+	defer cancel()
+	_, err = client.FirstNode(ctx)
+	return err
+}
+
+// withVerification runs install and reports a failing verify as a warning only
+func withVerification(label string, install, verify func() error) func() error {
+	return func() error {
+		if err := install(); err != nil {
+			return err
+		}
+
+		if common.IsDryRun() {
+			fmt.Printf("[dry-run] Would verify the %s installation\n", label)
+			return nil
+		}
+
+		if err := verify(); err != nil {
+			common.Warnf("%s verification failed: %v", label, err)
+		}
+		return nil
+	}
+}
+
+// runSteps executes steps in order, checkpointing each one in state. When
+// resuming, completed steps are skipped as long as they still verify; once an
+// incomplete or unverified step has to run, every following step runs as well.
+// With rollback, a failure undoes every step this run completed, including
+// the partially applied failing one, in reverse order. Components that were
+// already installed before the run are left alone.
+func runSteps(steps []step, state *installState, resume, rollback bool) error {
+	var ran []step
+	for _, s := range steps {
+		if resume && state.isCompleted(s.name) && s.verify != nil {
+			fmt.Printf("\n🔁 Re-verifying completed step: %s\n", s.name)
+			err := s.verify()
+			if err == nil {
+				fmt.Printf("⏭️ Skipping completed step: %s\n", s.name)
+				events.StepFinished(s.name, events.StatusSkipped, 0, "completed in a previous run")
+				continue
+			}
+			fmt.Printf("⚠️ Completed step %s no longer verifies (%v), running it again\n", s.name, err)
+			resume = false
+		}
+		if !state.isCompleted(s.name) {
+			resume = false
+		}
+
+		fmt.Printf("\n▶️ Step: %s\n", s.name)
+		events.StepStarted(s.name)
+		started := time.Now()
+		ran = append(ran, s)
+		if rollback && s.undo != nil && s.verify != nil && !common.IsDryRun() && s.verify() == nil {
+			// Already in place before this run, so a failure must not remove it
+			ran[len(ran)-1].undo = nil
+		}
+		if err := s.run(); err != nil {
+			events.StepFinished(s.name, events.StatusFailed, time.Since(started), err.Error())
+			if saveErr := state.markFailed(s.name, err); saveErr != nil {
+				common.Warnf("failed to save install state: %v", saveErr)
+			}
+
+			if rollback && rollbackSteps(ran, state) {
+				fmt.Println("💡 Fix the problem and run 'austinhome install' again, or pass --no-rollback to keep a failed install for debugging")
+				return fmt.Errorf("step %s failed and was rolled back: %v", s.name, err)
+			}
+			fmt.Printf("💡 Fix the problem and run 'austinhome install --resume' to continue from step %s\n", s.name)
+			return fmt.Errorf("step %s failed: %v", s.name, err)
+		}
+
+		events.StepFinished(s.name, events.StatusSucceeded, time.Since(started), "")
+		if err := state.markCompleted(s.name); err != nil {
+			common.Warnf("failed to save install state: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// rollbackSteps undoes ran in reverse order, continuing past failures so as
+// much as possible is cleaned up. It reports whether anything was undone.
+func rollbackSteps(ran []step, state *installState) bool {
+	var undone []string
+	for i := len(ran) - 1; i >= 0; i-- {
+		s := ran[i]
+		if s.undo == nil {
+			continue
+		}
+
+		if len(undone) == 0 {
+			fmt.Println("\n↩️ Rolling back components installed by this run...")
+		}
+		name := "rollback-" + s.name
+		events.StepStarted(name)
+		started := time.Now()
+		if err := s.undo(); err != nil {
+			events.StepFinished(name, events.StatusFailed, time.Since(started), err.Error())
+			common.Warnf("failed to roll back %s: %v", s.name, err)
+			continue
+		}
+		events.StepFinished(name, events.StatusSucceeded, time.Since(started), "")
+		undone = append(undone, s.name)
+	}
+
+	if len(undone) == 0 {
+		return false
+	}
+	if err := state.markRolledBack(undone); err != nil {
+		common.Warnf("failed to save install state: %v", err)
+	}
+	fmt.Printf("↩️ Rolled back: %s\n", strings.Join(undone, ", "))
+	return true
+}
+
+// resourceExists fails unless a resource of kind exists, see kube.Client.Exists
+func resourceExists(kind, namespace, name string) error {
+	client, err := kube.Get()
+	if err != nil {
+		return err
+	}
+	return client.Exists(context.Background(), kind, namespace, name)
+}
@@ -0,0 +1,140 @@
+package kube
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Client talks to the Kubernetes API. Typed resources go through Clientset,
+// CRDs like IPAddressPool or ClusterIssuer through Dynamic, with Mapper
+// resolving kind names to their resources.
+type Client struct {
+	Clientset kubernetes.Interface
+	Dynamic   dynamic.Interface
+	Mapper    meta.RESTMapper
+}
+
+var (
+	mu          sync.Mutex
+	client      *Client
+	kubeconfig  string
+	kubeContext string
+	// pinned keeps a client set by SetClient across Configure
+	pinned bool
+)
+
+// Configure selects the kubeconfig file ("" for the default loading rules) and
+// context ("" for the current one) used by the next call to Get
+func Configure(path, contextName string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	kubeconfig, kubeContext = path, contextName
+	if !pinned {
+		client = nil
+	}
+}
+
+// Kubeconfig returns the kubeconfig file and context selected by Configure
+func Kubeconfig() (path, contextName string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return kubeconfig, kubeContext
+}
+
+// SetClient replaces the shared client, e.g. with one built on fake clientsets.
+// It is kept when the install switches clusters, until SetClient(nil).
+func SetClient(c *Client) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	client, pinned = c, c != nil
+}
+
+// Get returns the shared client, connecting on first use. The connection is
+// deferred because the cluster usually does not exist yet when austinhome starts.
+func Get() (*Client, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if client != nil {
+		return client, nil
+	}
+
+	restConfig, err := loadRESTConfig(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	client = c
+	return client, nil
+}
+
+// NewForConfig builds a client for restConfig
+func NewForConfig(restConfig *rest.Config) (*Client, error) {
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %v", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %v", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %v", err)
+	}
+
+	// The shortcut expander resolves kubectl short names like crd for Exists
+	cached := memory.NewMemCacheClient(discoveryClient)
+	return &Client{
+		Clientset: clientset,
+		Dynamic:   dynamicClient,
+		Mapper:    restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil),
+	}, nil
+}
+
+// CurrentContext returns the current context of the kubeconfig at path, or of
+// the default loading rules when path is empty
+func CurrentContext(path string) (string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if path != "" {
+		rules.ExplicitPath = path
+	}
+
+	kubeconfig, err := rules.Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	return kubeconfig.CurrentContext, nil
+}
+
+func loadRESTConfig(path, contextName string) (*rest.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if path != "" {
+		rules.ExplicitPath = path
+	}
+
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %v", err)
+	}
+	return restConfig, nil
+}
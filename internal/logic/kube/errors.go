@@ -0,0 +1,44 @@
+package kube
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotReadyError is returned when pods do not become ready in time
+type NotReadyError struct {
+	Namespace string
+	Selector  string
+	Ready     int
+	Total     int
+	Timeout   time.Duration
+}
+
+func (e *NotReadyError) Error() string {
+	return fmt.Sprintf("timeout: %d/%d pods ready in %s (%s) after %v", e.Ready, e.Total, e.Namespace, selectorText(e.Selector), e.Timeout)
+}
+
+// NotFoundError is returned when a required resource does not exist
+type NotFoundError struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (e *NotFoundError) Error() string {
+	target := e.Kind
+	if e.Name != "" {
+		target += "/" + e.Name
+	}
+	if e.Namespace != "" {
+		return fmt.Sprintf("%s not found in %s", target, e.Namespace)
+	}
+	return fmt.Sprintf("%s not found", target)
+}
+
+func selectorText(selector string) string {
+	if selector == "" {
+		return "all pods"
+	}
+	return selector
+}
@@ -0,0 +1,229 @@
+package kube
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var ipAddressPool = schema.GroupVersionKind{Group: "metallb.io", Version: "v1beta1", Kind: "IPAddressPool"}
+
+// staleMapper misses every kind until Reset, like a discovery cache taken
+// before a CRD was installed
+type staleMapper struct {
+	meta.RESTMapper
+	fresh  meta.RESTMapper
+	resets int
+}
+
+func (m *staleMapper) Reset() {
+	m.resets++
+	m.RESTMapper = m.fresh
+}
+
+func poolMapper() *meta.DefaultRESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(ipAddressPool, meta.RESTScopeNamespace)
+	return mapper
+}
+
+func newPool(namespace, name string) *unstructured.Unstructured {
+	pool := &unstructured.Unstructured{}
+	pool.SetGroupVersionKind(ipAddressPool)
+	pool.SetNamespace(namespace)
+	pool.SetName(name)
+	return pool
+}
+
+func dynamicClient(objects ...runtime.Object) *fakedynamic.FakeDynamicClient {
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ipAddressPool.GroupVersion().WithResource("ipaddresspools"): "IPAddressPoolList"},
+		objects...)
+}
+
+func TestApplySecret(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewClientset()
+	c := &Client{Clientset: clientset}
+
+	if err := c.ApplySecret(ctx, "external-secrets", "store", map[string]string{"token": "one", "stale": "x"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	secret, err := clientset.CoreV1().Secrets("external-secrets").Get(ctx, "store", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Type != corev1.SecretTypeOpaque || secret.StringData["token"] != "one" {
+		t.Fatalf("created secret is %s with %v", secret.Type, secret.StringData)
+	}
+
+	// The API server folds StringData into Data, which the update must clear
+	secret.Data, secret.StringData = map[string][]byte{"token": []byte("one"), "stale": []byte("x")}, nil
+	if _, err := clientset.CoreV1().Secrets("external-secrets").Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.ApplySecret(ctx, "external-secrets", "store", map[string]string{"token": "two"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	secret, err = clientset.CoreV1().Secrets("external-secrets").Get(ctx, "store", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Data != nil || !maps.Equal(secret.StringData, map[string]string{"token": "two"}) {
+		t.Fatalf("updated secret has data %v and string data %v", secret.Data, secret.StringData)
+	}
+}
+
+func TestExists(t *testing.T) {
+	tests := []struct {
+		name      string
+		objects   []runtime.Object
+		kind      string
+		namespace string
+		resource  string
+		notFound  bool
+		wantErr   bool
+	}{
+		{name: "named", objects: []runtime.Object{newPool("metallb-system", "default")}, kind: "ipaddresspool", namespace: "metallb-system", resource: "default"},
+		{name: "plural kind", objects: []runtime.Object{newPool("metallb-system", "default")}, kind: "ipaddresspools", namespace: "metallb-system", resource: "default"},
+		{name: "named missing", objects: []runtime.Object{newPool("metallb-system", "other")}, kind: "ipaddresspool", namespace: "metallb-system", resource: "default", notFound: true},
+		{name: "any", objects: []runtime.Object{newPool("metallb-system", "default")}, kind: "ipaddresspool", namespace: "metallb-system"},
+		{name: "any in another namespace", objects: []runtime.Object{newPool("default", "default")}, kind: "ipaddresspool", namespace: "metallb-system", notFound: true},
+		{name: "none", kind: "ipaddresspool", namespace: "metallb-system", notFound: true},
+		{name: "unknown kind", kind: "clusterissuer", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{Dynamic: dynamicClient(tt.objects...), Mapper: poolMapper()}
+			err := c.Exists(context.Background(), tt.kind, tt.namespace, tt.resource)
+
+			var notFound *NotFoundError
+			switch {
+			case tt.notFound && !errors.As(err, &notFound):
+				t.Fatalf("got %v, want a NotFoundError", err)
+			case tt.wantErr && (err == nil || errors.As(err, &notFound)):
+				t.Fatalf("got %v, want an unknown kind error", err)
+			case !tt.notFound && !tt.wantErr && err != nil:
+				t.Fatalf("got %v, want it to exist", err)
+			}
+		})
+	}
+}
+
+func TestExistsRefreshesStaleDiscovery(t *testing.T) {
+	mapper := &staleMapper{RESTMapper: meta.NewDefaultRESTMapper(nil), fresh: poolMapper()}
+	c := &Client{Dynamic: dynamicClient(newPool("metallb-system", "default")), Mapper: mapper}
+
+	if err := c.Exists(context.Background(), "ipaddresspool", "metallb-system", "default"); err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	if mapper.resets != 1 {
+		t.Fatalf("discovery was reset %d times, want once", mapper.resets)
+	}
+
+	// A known kind must not drop the cache again
+	if err := c.Exists(context.Background(), "ipaddresspool", "metallb-system", "default"); err != nil {
+		t.Fatalf("Exists: %v", err)
+	}
+	if mapper.resets != 1 {
+		t.Fatalf("discovery was reset %d times, want once", mapper.resets)
+	}
+}
+
+func newPod(name string, ready bool) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "metallb-system", Labels: map[string]string{"app": "metallb"}},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func TestPodReady(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want bool
+	}{
+		{name: "ready", pod: newPod("a", true), want: true},
+		{name: "not ready", pod: newPod("a", false)},
+		{name: "no conditions", pod: &corev1.Pod{}},
+		{name: "other conditions", pod: &corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+		}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PodReady(tt.pod); got != tt.want {
+				t.Fatalf("PodReady = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWaitForPodsReady(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("already ready", func(t *testing.T) {
+		c := &Client{Clientset: fake.NewClientset(newPod("a", true), newPod("b", true))}
+		if err := c.WaitForPodsReady(ctx, "metallb-system", "app=metallb", time.Second); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("becomes ready", func(t *testing.T) {
+		clientset := fake.NewClientset(newPod("a", true), newPod("b", false))
+		watcher := watch.NewFake()
+		clientset.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(watcher, nil))
+		go watcher.Modify(newPod("b", true))
+
+		c := &Client{Clientset: clientset}
+		if err := c.WaitForPodsReady(ctx, "metallb-system", "app=metallb", 5*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		pods  []runtime.Object
+		ready int
+		total int
+	}{
+		{name: "no pods"},
+		{name: "one not ready", pods: []runtime.Object{newPod("a", true), newPod("b", false)}, ready: 1, total: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset(tt.pods...)
+			clientset.PrependWatchReactor("pods", k8stesting.DefaultWatchReactor(watch.NewFake(), nil))
+
+			c := &Client{Clientset: clientset}
+			err := c.WaitForPodsReady(ctx, "metallb-system", "app=metallb", 50*time.Millisecond)
+			var notReady *NotReadyError
+			if !errors.As(err, &notReady) {
+				t.Fatalf("got %v, want a NotReadyError", err)
+			}
+			if notReady.Ready != tt.ready || notReady.Total != tt.total {
+				t.Fatalf("got %d/%d ready, want %d/%d", notReady.Ready, notReady.Total, tt.ready, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// PodReady reports whether the pod has the Ready condition
+func PodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// ListPods returns the pods matching selector ("" for all) in namespace
+func (c *Client) ListPods(ctx context.Context, namespace, selector string) ([]corev1.Pod, error) {
+	pods, err := c.Clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %w", namespace, err)
+	}
+	return pods.Items, nil
+}
+
+// WaitForPodsReady watches the pods matching selector until at least one
+// exists and all of them are ready, or returns a *NotReadyError after timeout
+func (c *Client) WaitForPodsReady(ctx context.Context, namespace, selector string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pods := c.Clientset.CoreV1().Pods(namespace)
+	ready := map[string]bool{}
+	notReady := func() error {
+		readyCount := 0
+		for _, isReady := range ready {
+			if isReady {
+				readyCount++
+			}
+		}
+		return &NotReadyError{Namespace: namespace, Selector: selector, Ready: readyCount, Total: len(ready), Timeout: timeout}
+	}
+
+	// List, then watch from that version; start over whenever the server closes the watch
+	for {
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			if ctx.Err() != nil {
+				return notReady()
+			}
+			return fmt.Errorf("failed to list pods in %s: %w", namespace, err)
+		}
+
+		ready = make(map[string]bool, len(list.Items))
+		for i := range list.Items {
+			ready[list.Items[i].Name] = PodReady(&list.Items[i])
+		}
+		if allReady(ready) {
+			return nil
+		}
+
+		watcher, err := pods.Watch(ctx, metav1.ListOptions{
+			LabelSelector:   selector,
+			ResourceVersion: list.ResourceVersion,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return notReady()
+			}
+			return fmt.Errorf("failed to watch pods in %s: %w", namespace, err)
+		}
+
+		done, err := watchUntilReady(ctx, watcher, ready)
+		watcher.Stop()
+		if done {
+			return nil
+		}
+		if err != nil {
+			return notReady()
+		}
+	}
+}
+
+// watchUntilReady applies pod events to ready until every pod is ready (true),
+// the context expires (error) or the watch is closed (false, nil)
+func watchUntilReady(ctx context.Context, watcher watch.Interface, ready map[string]bool) (bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return false, nil
+			}
+
+			pod, isPod := event.Object.(*corev1.Pod)
+			if !isPod {
+				continue
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				ready[pod.Name] = PodReady(pod)
+			case watch.Deleted:
+				delete(ready, pod.Name)
+			}
+
+			if allReady(ready) {
+				return true, nil
+			}
+		}
+	}
+}
+
+func allReady(ready map[string]bool) bool {
+	if len(ready) == 0 {
+		return false
+	}
+	for _, isReady := range ready {
+		if !isReady {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+package kube
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Exists reports whether a resource of kind (a kubectl style name like
+// "ipaddresspool" or "clusterissuer") exists. An empty namespace means cluster
+// scope or all namespaces, an empty name means any resource of that kind.
+func (c *Client) Exists(ctx context.Context, kind, namespace, name string) error {
+	gvr, err := c.resourceNamed(kind)
+	if err != nil {
+		return err
+	}
+
+	resource := c.Dynamic.Resource(gvr)
+	if name != "" {
+		_, err = resource.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return &NotFoundError{Kind: kind, Namespace: namespace, Name: name}
+		}
+		return err
+	}
+
+	list, err := resource.Namespace(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(list.Items) == 0 {
+		return &NotFoundError{Kind: kind, Namespace: namespace}
+	}
+	return nil
+}
+
+// resourceNamed resolves a kubectl style kind name, refreshing the cached
+// discovery once in case its CRD was installed after the client connected
+func (c *Client) resourceNamed(kind string) (schema.GroupVersionResource, error) {
+	partial := schema.GroupVersionResource{Resource: kind}
+	gvr, err := c.Mapper.ResourceFor(partial)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := c.Mapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			gvr, err = c.Mapper.ResourceFor(partial)
+		}
+	}
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("unknown resource kind %s: %w", kind, err)
+	}
+	return gvr, nil
+}
+
+// FirstNode returns the name of the first cluster node
+func (c *Client) FirstNode(ctx context.Context) (string, error) {
+	nodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return "", fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if len(nodes.Items) == 0 {
+		return "", &NotFoundError{Kind: "node"}
+	}
+	return nodes.Items[0].Name, nil
+}
+
+// NodeWithLabelExists reports whether some node carries key=value
+func (c *Client) NodeWithLabelExists(ctx context.Context, key, value string) error {
+	selector := key + "=" + value
+	nodes, err := c.Clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: selector, Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+	if len(nodes.Items) == 0 {
+		return &NotFoundError{Kind: "node", Name: selector}
+	}
+	return nil
+}
+
+// LabelNode sets key=value on the node, overwriting any previous value
+func (c *Client) LabelNode(ctx context.Context, node, key, value string) error {
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, key, value)
+	_, err := c.Clientset.CoreV1().Nodes().Patch(ctx, node, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to label node %s: %w", node, err)
+	}
+	return nil
+}
+
+// ApplySecret creates an Opaque secret holding data, or replaces the data of an existing one
+func (c *Client) ApplySecret(ctx context.Context, namespace, name string, data map[string]string) error {
+	secrets := c.Clientset.CoreV1().Secrets(namespace)
+
+	existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Type:       corev1.SecretTypeOpaque,
+			StringData: data,
+		}
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create secret %s/%s: %w", namespace, name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+
+	// StringData is merged into Data, so clear Data to drop keys no longer present
+	existing.Data = nil
+	existing.StringData = data
+	if _, err := secrets.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// EnsureNamespace creates the namespace unless it already exists
+func (c *Client) EnsureNamespace(ctx context.Context, name string) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	_, err := c.Clientset.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteNamespace deletes the namespace, succeeding if it does not exist
+func (c *Client) DeleteNamespace(ctx context.Context, name string) error {
+	err := c.Clientset.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteIngressClass deletes the ingress class, succeeding if it does not exist
+func (c *Client) DeleteIngressClass(ctx context.Context, name string) error {
+	err := c.Clientset.NetworkingV1().IngressClasses().Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete ingress class %s: %w", name, err)
+	}
+	return nil
+}
+
+// LoadBalancerIP returns the first ingress IP of a LoadBalancer service, or "" if none is assigned yet
+func (c *Client) LoadBalancerIP(ctx context.Context, namespace, service string) (string, error) {
+	svc, err := c.Clientset.CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", &NotFoundError{Kind: "service", Namespace: namespace, Name: service}
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get service %s/%s: %w", namespace, service, err)
+	}
+
+	return ingressIP(svc), nil
+}
+
+// WaitForLoadBalancerIP watches a LoadBalancer service until an ingress IP is assigned
+func (c *Client) WaitForLoadBalancerIP(ctx context.Context, namespace, service string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	timedOut := fmt.Errorf("timeout: LoadBalancer IP not assigned to %s/%s after %v", namespace, service, timeout)
+	services := c.Clientset.CoreV1().Services(namespace)
+
+	// Get, then watch from that version; start over whenever the server closes the watch
+	for {
+		svc, err := services.Get(ctx, service, metav1.GetOptions{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", timedOut
+			}
+			if apierrors.IsNotFound(err) {
+				return "", &NotFoundError{Kind: "service", Namespace: namespace, Name: service}
+			}
+			return "", fmt.Errorf("failed to get service %s/%s: %w", namespace, service, err)
+		}
+		if ip := ingressIP(svc); ip != "" {
+			return ip, nil
+		}
+
+		watcher, err := services.Watch(ctx, metav1.ListOptions{
+			FieldSelector:   "metadata.name=" + service,
+			ResourceVersion: svc.ResourceVersion,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", timedOut
+			}
+			return "", fmt.Errorf("failed to watch service %s/%s: %w", namespace, service, err)
+		}
+
+		for event := range watcher.ResultChan() {
+			if updated, isService := event.Object.(*corev1.Service); isService {
+				if ip := ingressIP(updated); ip != "" {
+					watcher.Stop()
+					return ip, nil
+				}
+			}
+		}
+		watcher.Stop()
+
+		if ctx.Err() != nil {
+			return "", timedOut
+		}
+	}
+}
+
+func ingressIP(svc *corev1.Service) string {
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return ingress.IP
+		}
+	}
+	return ""
+}
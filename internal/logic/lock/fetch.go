@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"austinhome/internal/logic/charts"
+	"austinhome/internal/logic/config"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 2 * time.Minute}
+
+// Fetch downloads url into memory
+func Fetch(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", url, resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %v", url, err)
+	}
+	return data, nil
+}
+
+// FetchChart downloads a chart archive into memory
+func FetchChart(ref config.ChartRef) ([]byte, error) {
+	chartPath, err := charts.Pull(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chart %s: %v", ref, err)
+	}
+	return data, nil
+}
+
+// Update downloads every artifact referenced by cfg and records its digest
+func Update(cfg *config.Config) (*Lock, error) {
+	l := &Lock{Version: lockVersion}
+
+	for _, url := range cfg.URLs() {
+		fmt.Printf("📥 Locking %s\n", url)
+		data, err := Fetch(url)
+		if err != nil {
+			return nil, err
+		}
+		l.Files = append(l.Files, File{URL: url, SHA256: Digest(data)})
+	}
+
+	for _, ref := range cfg.Charts() {
+		fmt.Printf("📥 Locking chart %s from %s\n", ref, ref.RepoURL)
+		data, err := FetchChart(ref)
+		if err != nil {
+			return nil, err
+		}
+		l.Charts = append(l.Charts, Chart{Name: ref.Name, RepoURL: ref.RepoURL, Version: ref.Version, SHA256: Digest(data)})
+	}
+
+	return l, nil
+}
+
+// Diff describes how next differs from l, one line per changed artifact
+func (l *Lock) Diff(next *Lock) []string {
+	var changes []string
+	for _, file := range next.Files {
+		previous, ok := l.FileDigest(file.URL)
+		switch {
+		case !ok:
+			changes = append(changes, "added "+file.URL)
+		case previous != file.SHA256:
+			changes = append(changes, "changed "+file.URL)
+		}
+	}
+	for _, chart := range next.Charts {
+		ref := config.ChartRef{Name: chart.Name, RepoURL: chart.RepoURL, Version: chart.Version}
+		previous, ok := l.ChartDigest(ref)
+		switch {
+		case !ok:
+			changes = append(changes, "added chart "+ref.String())
+		case previous != chart.SHA256:
+			changes = append(changes, "changed chart "+ref.String())
+		}
+	}
+	return changes
+}
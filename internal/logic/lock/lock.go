@@ -0,0 +1,170 @@
+// Package lock pins the SHA-256 digest of every remote manifest, values file
+// and chart archive, so that an upstream change cannot silently alter what is
+// applied to the cluster.
+package lock
+
+import (
+	"austinhome/internal/logic/config"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileName is the lock file used when --lock is not given
+const DefaultFileName = "austinhome.lock"
+
+const lockVersion = 1
+
+// Lock lists the expected digest of every remote artifact
+type Lock struct {
+	Version int     `yaml:"version"`
+	Files   []File  `yaml:"files"`
+	Charts  []Chart `yaml:"charts"`
+}
+
+// File is a manifest or values file
+type File struct {
+	URL    string `yaml:"url"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// Chart is a chart archive
+type Chart struct {
+	Name    string `yaml:"name"`
+	RepoURL string `yaml:"repoURL"`
+	Version string `yaml:"version"`
+	SHA256  string `yaml:"sha256"`
+}
+
+// MismatchError is returned when an artifact does not match its locked digest
+type MismatchError struct {
+	Artifact string
+	Expected string
+	Actual   string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s (run 'austinhome lock update' if the change is intended)",
+		e.Artifact, e.Expected, e.Actual)
+}
+
+// Load reads the lock file at path
+func Load(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("lock file %s not found, run 'austinhome lock update' to create it", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %v", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	l := &Lock{}
+	if err := decoder.Decode(l); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %v", path, err)
+	}
+	if l.Version != lockVersion {
+		return nil, fmt.Errorf("unsupported lock file version %d in %s", l.Version, path)
+	}
+	return l, nil
+}
+
+// Save writes the lock file to path
+func (l *Lock) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by 'austinhome lock update'. Do not edit by hand.\n")
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(l); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write lock file: %v", err)
+	}
+	return nil
+}
+
+// FileDigest returns the locked digest of url
+func (l *Lock) FileDigest(url string) (string, bool) {
+	for _, file := range l.Files {
+		if file.URL == url {
+			return file.SHA256, true
+		}
+	}
+	return "", false
+}
+
+// ChartDigest returns the locked digest of a chart archive
+func (l *Lock) ChartDigest(ref config.ChartRef) (string, bool) {
+	for _, chart := range l.Charts {
+		if chart.Name == ref.Name && chart.RepoURL == ref.RepoURL && chart.Version == ref.Version {
+			return chart.SHA256, true
+		}
+	}
+	return "", false
+}
+
+// Covers fails unless every artifact referenced by cfg has a locked digest
+func (l *Lock) Covers(cfg *config.Config) error {
+	var missing []string
+	for _, url := range cfg.URLs() {
+		if _, ok := l.FileDigest(url); !ok {
+			missing = append(missing, url)
+		}
+	}
+	for _, ref := range cfg.Charts() {
+		if _, ok := l.ChartDigest(ref); !ok {
+			missing = append(missing, fmt.Sprintf("chart %s from %s", ref, ref.RepoURL))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("lock file is out of date, run 'austinhome lock update'. Missing:\n  - %s", strings.Join(missing, "\n  - "))
+	}
+	return nil
+}
+
+// VerifyFile checks a downloaded manifest or values file against its locked digest
+func (l *Lock) VerifyFile(url string, data []byte) error {
+	expected, ok := l.FileDigest(url)
+	if !ok {
+		return fmt.Errorf("%s is not in the lock file, run 'austinhome lock update'", url)
+	}
+	return verify(url, expected, data)
+}
+
+// VerifyChart checks a chart archive against its locked digest
+func (l *Lock) VerifyChart(ref config.ChartRef, data []byte) error {
+	expected, ok := l.ChartDigest(ref)
+	if !ok {
+		return fmt.Errorf("chart %s from %s is not in the lock file, run 'austinhome lock update'", ref, ref.RepoURL)
+	}
+	return verify("chart "+ref.String(), expected, data)
+}
+
+// Digest returns the hex encoded SHA-256 of data
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func verify(artifact, expected string, data []byte) error {
+	if actual := Digest(data); actual != expected {
+		return &MismatchError{Artifact: artifact, Expected: expected, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package lock
+
+import (
+	"austinhome/internal/logic/config"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCommittedLockCoversDefaults keeps the lock file at the repository root
+// in sync with the defaults of config.go for every provider. Generating the
+// lock needs network access, so the check only runs once it is committed.
+func TestCommittedLockCoversDefaults(t *testing.T) {
+	path := filepath.Join("..", "..", "..", DefaultFileName)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("%s is not committed yet, run 'austinhome lock update' with network access and commit it", DefaultFileName)
+	}
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, provider := range []string{config.ProviderColima, config.ProviderK3s, config.ProviderK3d, config.ProviderKind, config.ProviderExisting} {
+		c := config.Default()
+		c.Cluster.Provider = provider
+		if err := l.Covers(c); err != nil {
+			t.Errorf("%s provider: %v", provider, err)
+		}
+	}
+}
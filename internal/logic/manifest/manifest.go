@@ -0,0 +1,138 @@
+// Package manifest records what the installer actually created on this
+// machine, so that uninstall removes those items and leaves tools and
+// directories the user already had alone.
+package manifest
+
+import (
+	"austinhome/internal/logic/common"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const fileName = "manifest.json"
+
+// Manifest lists the items created by austinhome
+type Manifest struct {
+	// Formulae are Homebrew formulae installed by austinhome
+	Formulae []string `json:"formulae,omitempty"`
+	// Directories are directories that did not exist before austinhome created them
+	Directories []string `json:"directories,omitempty"`
+	// Files are files austinhome keeps outside of those directories, like the install state
+	Files     []string  `json:"files,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Path returns the location of the manifest file
+func Path() (string, error) {
+	dir, err := common.AppDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads the manifest, an empty one when nothing was recorded yet
+func Load() (*Manifest, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install manifest: %v", err)
+	}
+
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse install manifest %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// AddFormula records a Homebrew formula installed by austinhome
+func AddFormula(formula string) error {
+	return update(func(m *Manifest) {
+		if !slices.Contains(m.Formulae, formula) {
+			m.Formulae = append(m.Formulae, formula)
+		}
+	})
+}
+
+// AddDirectory records a directory created by austinhome
+func AddDirectory(dir string) error {
+	return update(func(m *Manifest) {
+		if !slices.Contains(m.Directories, dir) {
+			m.Directories = append(m.Directories, dir)
+		}
+	})
+}
+
+// AddFile records a file created by austinhome
+func AddFile(file string) error {
+	return update(func(m *Manifest) {
+		if !slices.Contains(m.Files, file) {
+			m.Files = append(m.Files, file)
+		}
+	})
+}
+
+// Remove deletes the manifest once everything it lists is gone
+func Remove() error {
+	if common.IsDryRun() {
+		return nil
+	}
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove install manifest: %v", err)
+	}
+	return nil
+}
+
+func update(change func(*Manifest)) error {
+	// A dry run creates nothing, so there is nothing to record
+	if common.IsDryRun() {
+		return nil
+	}
+
+	m, err := Load()
+	if err != nil {
+		return err
+	}
+	change(m)
+	return m.save()
+}
+
+func (m *Manifest) save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %v", err)
+	}
+
+	m.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write install manifest: %v", err)
+	}
+	return nil
+}
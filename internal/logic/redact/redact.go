@@ -0,0 +1,107 @@
+// Package redact masks registered secret values in everything austinhome
+// prints or emits, so tokens never reach the terminal scrollback or logs.
+package redact
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every registered secret
+const Mask = "********"
+
+// minLength keeps short values like "dev" from masking unrelated output
+const minLength = 6
+
+var (
+	mu      sync.RWMutex
+	secrets []string
+)
+
+// Register marks value as secret from now on
+func Register(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minLength {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, existing := range secrets {
+		if existing == value {
+			return
+		}
+	}
+	secrets = append(secrets, value)
+}
+
+// String returns s with every registered secret masked
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, Mask)
+	}
+	return s
+}
+
+// Writer masks secrets in everything written to it. Each write is forwarded
+// at once, except for a trailing part that could be the start of a secret
+// split across writes, so prompts without a newline still show up. Call
+// Flush once done.
+type Writer struct {
+	out io.Writer
+	buf bytes.Buffer
+}
+
+// NewWriter returns a Writer forwarding masked output to out
+func NewWriter(out io.Writer) *Writer {
+	return &Writer{out: out}
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+
+	masked := String(w.buf.String())
+	keep := partialSecret(masked)
+	w.buf.Reset()
+	w.buf.WriteString(masked[len(masked)-keep:])
+
+	if _, err := io.WriteString(w.out, masked[:len(masked)-keep]); err != nil {
+		return len(p), err
+	}
+	return len(p), nil
+}
+
+// Flush writes any held back output
+func (w *Writer) Flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := io.WriteString(w.out, String(w.buf.String()))
+	w.buf.Reset()
+	return err
+}
+
+// partialSecret returns the length of the longest suffix of s that a
+// registered secret starts with, which is never the whole secret
+func partialSecret(s string) int {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	longest := 0
+	for _, secret := range secrets {
+		for n := min(len(secret)-1, len(s)); n > longest; n-- {
+			if strings.HasPrefix(secret, s[len(s)-n:]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,52 @@
+package redact
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriterMasksSecretSplitAcrossWrites(t *testing.T) {
+	Register("glpat-split-secret")
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	for _, chunk := range []string{"token=glpat-sp", "lit-sec", "ret done\n"} {
+		w.Write([]byte(chunk))
+	}
+	w.Flush()
+
+	if got, want := out.String(), "token="+Mask+" done\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriterForwardsPartialLines(t *testing.T) {
+	Register("glpat-prompt-secret")
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	w.Write([]byte("Password: "))
+
+	if got := out.String(); got != "Password: " {
+		t.Fatalf("prompt without newline was held back, got %q", got)
+	}
+}
+
+func TestWriterHoldsBackOnlyPossibleSecretStart(t *testing.T) {
+	Register("glpat-held-secret")
+
+	var out bytes.Buffer
+	w := NewWriter(&out)
+	w.Write([]byte("progress 50% glpat-he"))
+
+	if got := out.String(); got != "progress 50% " {
+		t.Fatalf("got %q, want the output up to the possible secret", got)
+	}
+
+	w.Write([]byte("lp is not it"))
+	w.Flush()
+	if got := out.String(); !strings.HasSuffix(got, "glpat-help is not it") {
+		t.Fatalf("held back output was lost, got %q", got)
+	}
+}
@@ -0,0 +1,58 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const awsSecretName = "aws-eso-secret"
+
+// aws reads from AWS Secrets Manager with a static access key
+type aws struct {
+	cfg       config.AWSStoreConfig
+	name      string
+	namespace string
+}
+
+func (a *aws) Name() string {
+	return config.SecretStoreAWS
+}
+
+func (a *aws) Credentials() []Credential {
+	return []Credential{
+		{Key: "access-key-id", Env: "AWS_ACCESS_KEY_ID", Prompt: "AWS access key ID"},
+		{Key: "secret-access-key", Env: "AWS_SECRET_ACCESS_KEY", Prompt: "AWS secret access key"},
+	}
+}
+
+func (a *aws) Secret() (string, string) {
+	return a.namespace, awsSecretName
+}
+
+func (a *aws) SecretData(credentials map[string]string) (map[string]string, error) {
+	return passthrough(credentials, a.Credentials())
+}
+
+func (a *aws) ManifestURL() string {
+	return ""
+}
+
+func (a *aws) Objects() []*unstructured.Unstructured {
+	return []*unstructured.Unstructured{
+		clusterSecretStore(a.name, "aws", map[string]any{
+			"service": "SecretsManager",
+			"region":  a.cfg.Region,
+			"auth": map[string]any{
+				"secretRef": map[string]any{
+					"accessKeyIDSecretRef":     secretKeyRef(a.namespace, awsSecretName, "access-key-id"),
+					"secretAccessKeySecretRef": secretKeyRef(a.namespace, awsSecretName, "secret-access-key"),
+				},
+			},
+		}),
+	}
+}
+
+func (a *aws) StoreName() string {
+	return a.name
+}
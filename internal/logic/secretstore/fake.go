@@ -0,0 +1,55 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fake serves static data from the store itself, for local testing without a backend
+type fake struct {
+	cfg  config.FakeStoreConfig
+	name string
+}
+
+func (f *fake) Name() string {
+	return config.SecretStoreFake
+}
+
+func (f *fake) Credentials() []Credential {
+	return nil
+}
+
+func (f *fake) Secret() (string, string) {
+	return "", ""
+}
+
+func (f *fake) SecretData(map[string]string) (map[string]string, error) {
+	return nil, nil
+}
+
+func (f *fake) ManifestURL() string {
+	return ""
+}
+
+func (f *fake) Objects() []*unstructured.Unstructured {
+	keys := make([]string, 0, len(f.cfg.Data))
+	for key := range f.cfg.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var data []any
+	for _, key := range keys {
+		data = append(data, map[string]any{"key": key, "value": f.cfg.Data[key]})
+	}
+
+	return []*unstructured.Unstructured{
+		clusterSecretStore(f.name, "fake", map[string]any{"data": data}),
+	}
+}
+
+func (f *fake) StoreName() string {
+	return f.name
+}
@@ -0,0 +1,114 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/redact"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FileSecretName is the Secret the file contents are copied into. ExternalSecrets
+// reference it as remoteRef.key with the file key as remoteRef.property.
+const FileSecretName = "austinhome-file-secrets"
+
+const fileServiceAccount = "austinhome-secretstore"
+
+var secretKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// file copies a local YAML file into a dedicated namespace and serves it
+// through the ESO kubernetes provider
+type file struct {
+	cfg  config.FileStoreConfig
+	name string
+}
+
+func (f *file) Name() string {
+	return config.SecretStoreFile
+}
+
+func (f *file) Credentials() []Credential {
+	return nil
+}
+
+func (f *file) Secret() (string, string) {
+	return f.cfg.Namespace, FileSecretName
+}
+
+func (f *file) SecretData(map[string]string) (map[string]string, error) {
+	raw, err := os.ReadFile(f.cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %v", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(raw))
+	var data map[string]string
+	if err := decoder.Decode(&data); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s, expected key: value pairs: %v", f.cfg.Path, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("secrets file %s is empty", f.cfg.Path)
+	}
+
+	for key := range data {
+		if !secretKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid key %q in secrets file %s: only letters, digits, '-', '_' and '.' are allowed", key, f.cfg.Path)
+		}
+		redact.Register(data[key])
+	}
+	return data, nil
+}
+
+func (f *file) ManifestURL() string {
+	return ""
+}
+
+func (f *file) Objects() []*unstructured.Unstructured {
+	namespace := f.cfg.Namespace
+
+	return []*unstructured.Unstructured{
+		object("v1", "Namespace", "", namespace, nil),
+		object("v1", "ServiceAccount", namespace, fileServiceAccount, nil),
+		object("rbac.authorization.k8s.io/v1", "Role", namespace, fileServiceAccount, map[string]any{
+			"rules": []any{
+				map[string]any{
+					"apiGroups": []any{""},
+					"resources": []any{"secrets"},
+					"verbs":     []any{"get", "list", "watch"},
+				},
+			},
+		}),
+		object("rbac.authorization.k8s.io/v1", "RoleBinding", namespace, fileServiceAccount, map[string]any{
+			"roleRef": map[string]any{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "Role",
+				"name":     fileServiceAccount,
+			},
+			"subjects": []any{
+				map[string]any{"kind": "ServiceAccount", "name": fileServiceAccount, "namespace": namespace},
+			},
+		}),
+		clusterSecretStore(f.name, "kubernetes", map[string]any{
+			"remoteNamespace": namespace,
+			"server": map[string]any{
+				"caProvider": map[string]any{
+					"type":      "ConfigMap",
+					"name":      "kube-root-ca.crt",
+					"key":       "ca.crt",
+					"namespace": namespace,
+				},
+			},
+			"auth": map[string]any{
+				"serviceAccount": map[string]any{"name": fileServiceAccount, "namespace": namespace},
+			},
+		}),
+	}
+}
+
+func (f *file) StoreName() string {
+	return f.name
+}
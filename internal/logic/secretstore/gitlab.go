@@ -0,0 +1,44 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// GitLabPATVar holds the GitLab PAT when no flag is given
+const GitLabPATVar = "AUSTINHOME_GITLAB_PAT"
+
+// gitlab stores a PAT and applies a ClusterSecretStore manifest that references it
+type gitlab struct {
+	manifestURL string
+	namespace   string
+}
+
+func (g *gitlab) Name() string {
+	return config.SecretStoreGitLab
+}
+
+func (g *gitlab) Credentials() []Credential {
+	return []Credential{{Key: "token", Env: GitLabPATVar, Prompt: "GitLab PAT (Personal Access Token)"}}
+}
+
+func (g *gitlab) Secret() (string, string) {
+	return g.namespace, "gitlab-eso-secret"
+}
+
+func (g *gitlab) SecretData(credentials map[string]string) (map[string]string, error) {
+	return passthrough(credentials, g.Credentials())
+}
+
+func (g *gitlab) ManifestURL() string {
+	return g.manifestURL
+}
+
+func (g *gitlab) Objects() []*unstructured.Unstructured {
+	return nil
+}
+
+func (g *gitlab) StoreName() string {
+	return ""
+}
@@ -0,0 +1,106 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// esoAPIVersion is the External Secrets API served by the installed chart
+const esoAPIVersion = "external-secrets.io/v1"
+
+// Credential is a value resolved before install and kept in the provider's credential Secret
+type Credential struct {
+	// Key is the key within the credential Secret
+	Key string
+	// Env is the environment variable the value is read from when set
+	Env string
+	// Prompt names the value when asking for it on a terminal
+	Prompt string
+}
+
+// Provider bootstraps the ClusterSecretStore ESO reads secrets through
+type Provider interface {
+	// Name identifies the provider in config and messages
+	Name() string
+	// Credentials lists the values that must be resolved before install
+	Credentials() []Credential
+	// Secret returns the namespace and name of the credential Secret, an empty name if none is needed
+	Secret() (namespace, name string)
+	// SecretData returns the credential Secret contents for the resolved credentials
+	SecretData(credentials map[string]string) (map[string]string, error)
+	// ManifestURL returns a remote ClusterSecretStore manifest, empty when the store is generated
+	ManifestURL() string
+	// Objects returns the generated ClusterSecretStore along with the resources it needs, in apply order
+	Objects() []*unstructured.Unstructured
+	// StoreName returns the ClusterSecretStore name, empty when the remote manifest defines it
+	StoreName() string
+}
+
+// New returns the provider selected by cfg.Components.ExternalSecrets.SecretStore.Provider.
+// Credential Secrets are created in namespace unless the provider keeps its own.
+func New(cfg *config.Config, namespace string) (Provider, error) {
+	eso := cfg.Components.ExternalSecrets
+	store := eso.SecretStore
+
+	switch store.Provider {
+	case config.SecretStoreGitLab:
+		return &gitlab{manifestURL: eso.ClusterSecretStoreURL, namespace: namespace}, nil
+	case config.SecretStoreVault:
+		return &vault{cfg: store.Vault, name: store.Name, namespace: namespace}, nil
+	case config.SecretStoreAWS:
+		return &aws{cfg: store.AWS, name: store.Name, namespace: namespace}, nil
+	case config.SecretStoreFake:
+		return &fake{cfg: store.Fake, name: store.Name}, nil
+	case config.SecretStoreFile:
+		return &file{cfg: store.File, name: store.Name}, nil
+	default:
+		return nil, fmt.Errorf("unknown secret store provider %q", store.Provider)
+	}
+}
+
+// clusterSecretStore builds a ClusterSecretStore using provider as spec.provider.<kind>
+func clusterSecretStore(name, kind string, provider map[string]any) *unstructured.Unstructured {
+	return object(esoAPIVersion, "ClusterSecretStore", "", name, map[string]any{
+		"spec": map[string]any{
+			"provider": map[string]any{kind: provider},
+		},
+	})
+}
+
+// object builds an unstructured resource with fields merged into its top level
+func object(apiVersion, kind, namespace, name string, fields map[string]any) *unstructured.Unstructured {
+	metadata := map[string]any{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+
+	content := map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   metadata,
+	}
+	for key, value := range fields {
+		content[key] = value
+	}
+	return &unstructured.Unstructured{Object: content}
+}
+
+// secretKeyRef points a store at one key of a credential Secret
+func secretKeyRef(namespace, name, key string) map[string]any {
+	return map[string]any{"name": name, "key": key, "namespace": namespace}
+}
+
+// passthrough returns the resolved credentials as the Secret data
+func passthrough(credentials map[string]string, required []Credential) (map[string]string, error) {
+	data := map[string]string{}
+	for _, credential := range required {
+		value, ok := credentials[credential.Key]
+		if !ok || value == "" {
+			return nil, fmt.Errorf("missing %s", credential.Prompt)
+		}
+		data[credential.Key] = value
+	}
+	return data, nil
+}
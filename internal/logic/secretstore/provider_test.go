@@ -0,0 +1,218 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+	"bytes"
+	"flag"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// storeConfig returns the defaults with the secret store provider replaced
+func storeConfig(provider string, configure func(*config.SecretStoreConfig)) *config.Config {
+	c := config.Default()
+	c.Components.ExternalSecrets.SecretStore.Provider = provider
+	if configure != nil {
+		configure(&c.Components.ExternalSecrets.SecretStore)
+	}
+	return c
+}
+
+// TestObjects compares the rendered store objects with testdata/<name>.yaml.
+// Run with -update after an intended change and review the diff.
+func TestObjects(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{name: "vault-token", cfg: storeConfig(config.SecretStoreVault, func(s *config.SecretStoreConfig) {
+			s.Vault.Server = "https://vault.example.com:8200"
+		})},
+		{name: "vault-approle", cfg: storeConfig(config.SecretStoreVault, func(s *config.SecretStoreConfig) {
+			s.Vault.Server, s.Vault.Path, s.Vault.Version = "https://vault.example.com:8200", "kv", "v1"
+			s.Vault.Auth, s.Vault.RoleID = config.VaultAuthAppRole, "role-1234"
+		})},
+		{name: "aws", cfg: storeConfig(config.SecretStoreAWS, func(s *config.SecretStoreConfig) {
+			s.AWS.Region = "eu-west-1"
+		})},
+		{name: "file", cfg: storeConfig(config.SecretStoreFile, func(s *config.SecretStoreConfig) {
+			s.File.Path = "secrets.yaml"
+		})},
+		{name: "fake", cfg: storeConfig(config.SecretStoreFake, func(s *config.SecretStoreConfig) {
+			s.Fake.Data = map[string]string{"password": "hunter2", "api-key": "abc"}
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(tt.cfg, "external-secrets")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if provider.ManifestURL() != "" {
+				t.Fatalf("generated store %s also has manifest %s", provider.Name(), provider.ManifestURL())
+			}
+
+			var buf bytes.Buffer
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			for _, obj := range provider.Objects() {
+				if err := encoder.Encode(obj.Object); err != nil {
+					t.Fatal(err)
+				}
+			}
+			encoder.Close()
+
+			golden := filepath.Join("testdata", tt.name+".yaml")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("objects differ from %s, got:\n%s", golden, buf.String())
+			}
+
+			// The store must be last and named as reported, it is what install verifies
+			objects := provider.Objects()
+			store := objects[len(objects)-1]
+			if store.GetAPIVersion() != esoAPIVersion || store.GetKind() != "ClusterSecretStore" || store.GetName() != provider.StoreName() {
+				t.Errorf("last object is %s %s %s, want ClusterSecretStore %s", store.GetAPIVersion(), store.GetKind(), store.GetName(), provider.StoreName())
+			}
+		})
+	}
+}
+
+func TestGitLab(t *testing.T) {
+	c := config.Default()
+	provider, err := New(c, "external-secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if provider.ManifestURL() != c.Components.ExternalSecrets.ClusterSecretStoreURL || provider.Objects() != nil || provider.StoreName() != "" {
+		t.Fatalf("gitlab store applies %q and generates %d object(s) named %q, want only the remote manifest",
+			provider.ManifestURL(), len(provider.Objects()), provider.StoreName())
+	}
+	// The remote manifest references this Secret by name
+	if namespace, name := provider.Secret(); namespace != "external-secrets" || name != "gitlab-eso-secret" {
+		t.Fatalf("secret is %s/%s", namespace, name)
+	}
+
+	data, err := provider.SecretData(map[string]string{"token": "glpat-abc"})
+	if err != nil || !maps.Equal(data, map[string]string{"token": "glpat-abc"}) {
+		t.Fatalf("got %v (%v)", data, err)
+	}
+}
+
+func TestSecretData(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		credentials map[string]string
+		want        map[string]string
+		wantErr     string
+	}{
+		{
+			name:        "vault token",
+			cfg:         storeConfig(config.SecretStoreVault, nil),
+			credentials: map[string]string{"token": "hvs.abc", "unused": "x"},
+			want:        map[string]string{"token": "hvs.abc"},
+		},
+		{
+			name: "vault approle",
+			cfg: storeConfig(config.SecretStoreVault, func(s *config.SecretStoreConfig) {
+				s.Vault.Auth = config.VaultAuthAppRole
+			}),
+			credentials: map[string]string{"secret-id": "s3cr3t"},
+			want:        map[string]string{"secret-id": "s3cr3t"},
+		},
+		{
+			name:        "aws",
+			cfg:         storeConfig(config.SecretStoreAWS, nil),
+			credentials: map[string]string{"access-key-id": "AKIA", "secret-access-key": "secret"},
+			want:        map[string]string{"access-key-id": "AKIA", "secret-access-key": "secret"},
+		},
+		{
+			name:        "aws missing secret key",
+			cfg:         storeConfig(config.SecretStoreAWS, nil),
+			credentials: map[string]string{"access-key-id": "AKIA", "secret-access-key": ""},
+			wantErr:     "AWS secret access key",
+		},
+		{
+			name:    "gitlab missing token",
+			cfg:     config.Default(),
+			wantErr: "GitLab PAT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := New(tt.cfg, "external-secrets")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := provider.SecretData(tt.credentials)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error about %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !maps.Equal(data, tt.want) {
+				t.Fatalf("got %v (%v), want %v", data, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSecretData(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr string
+	}{
+		{name: "pairs", content: "db.password: hunter2\napi-key: abc\n", want: map[string]string{"db.password": "hunter2", "api-key": "abc"}},
+		{name: "empty", content: "", wantErr: "invalid secrets file"},
+		{name: "no pairs", content: "{}\n", wantErr: "is empty"},
+		{name: "nested", content: "db:\n  password: hunter2\n", wantErr: "expected key: value pairs"},
+		{name: "invalid key", content: "db/password: hunter2\n", wantErr: "invalid key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "secrets.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			provider, err := New(storeConfig(config.SecretStoreFile, func(s *config.SecretStoreConfig) { s.File.Path = path }), "external-secrets")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := provider.SecretData(nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || !maps.Equal(data, tt.want) {
+				t.Fatalf("got %v (%v), want %v", data, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package secretstore
+
+import (
+	"austinhome/internal/logic/config"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Environment variables the Vault credentials are read from
+const (
+	VaultTokenVar    = "AUSTINHOME_VAULT_TOKEN"
+	VaultSecretIDVar = "AUSTINHOME_VAULT_SECRET_ID"
+)
+
+const vaultSecretName = "vault-eso-secret"
+
+// vault reads from a HashiCorp Vault KV engine with token or AppRole auth
+type vault struct {
+	cfg       config.VaultStoreConfig
+	name      string
+	namespace string
+}
+
+func (v *vault) Name() string {
+	return config.SecretStoreVault
+}
+
+func (v *vault) Credentials() []Credential {
+	if v.cfg.Auth == config.VaultAuthAppRole {
+		return []Credential{{Key: "secret-id", Env: VaultSecretIDVar, Prompt: "Vault AppRole secret ID"}}
+	}
+	return []Credential{{Key: "token", Env: VaultTokenVar, Prompt: "Vault token"}}
+}
+
+func (v *vault) Secret() (string, string) {
+	return v.namespace, vaultSecretName
+}
+
+func (v *vault) SecretData(credentials map[string]string) (map[string]string, error) {
+	return passthrough(credentials, v.Credentials())
+}
+
+func (v *vault) ManifestURL() string {
+	return ""
+}
+
+func (v *vault) Objects() []*unstructured.Unstructured {
+	auth := map[string]any{
+		"tokenSecretRef": secretKeyRef(v.namespace, vaultSecretName, "token"),
+	}
+	if v.cfg.Auth == config.VaultAuthAppRole {
+		auth = map[string]any{
+			"appRole": map[string]any{
+				"path":      v.cfg.AppRolePath,
+				"roleId":    v.cfg.RoleID,
+				"secretRef": secretKeyRef(v.namespace, vaultSecretName, "secret-id"),
+			},
+		}
+	}
+
+	return []*unstructured.Unstructured{
+		clusterSecretStore(v.name, "vault", map[string]any{
+			"server":  v.cfg.Server,
+			"path":    v.cfg.Path,
+			"version": v.cfg.Version,
+			"auth":    auth,
+		}),
+	}
+}
+
+func (v *vault) StoreName() string {
+	return v.name
+}
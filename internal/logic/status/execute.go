@@ -0,0 +1,101 @@
+package status
+
+import (
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/events"
+	"austinhome/internal/logic/install"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Options controls how Execute checks the cluster
+type Options struct {
+	// Runner executes external commands, defaults to common.ExecRunner
+	Runner common.Runner
+	// Config selects the cluster provider whose kubeconfig context is checked, defaults to config.Default()
+	Config *config.Config
+}
+
+// Execute checks every managed component, prints a status table and returns
+// the statuses along with an error if any component is degraded
+func Execute(opts Options) ([]install.ComponentStatus, error) {
+	common.SetRunner(opts.Runner)
+
+	cfg := opts.Config
+	if cfg == nil {
+		cfg = config.Default()
+	}
+	provider, err := cluster.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cluster.Bind(provider)
+
+	fmt.Println("🔍 Checking component health...")
+	statuses, err := install.CheckComponents(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Println()
+	renderTable(os.Stdout, statuses)
+
+	var degraded []string
+	for _, status := range statuses {
+		health := "ok"
+		if !status.Healthy() {
+			health = "degraded"
+			degraded = append(degraded, status.Component)
+		}
+		events.Emit(events.Event{Type: events.TypeComponent, Status: health, Data: status})
+	}
+
+	if len(degraded) > 0 {
+		return statuses, fmt.Errorf("%d component(s) degraded: %s", len(degraded), strings.Join(degraded, ", "))
+	}
+	return statuses, nil
+}
+
+func renderTable(out io.Writer, statuses []install.ComponentStatus) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "COMPONENT\tSTATUS\tVERSION\tPODS\tRESOURCES\tPROBLEMS")
+
+	for _, status := range statuses {
+		health := "✅ ok"
+		if !status.Healthy() {
+			health = "❌ degraded"
+		}
+
+		version := status.Version
+		if version == "" {
+			version = "-"
+		}
+
+		pods := "-"
+		if status.PodsTotal > 0 {
+			pods = fmt.Sprintf("%d/%d", status.PodsReady, status.PodsTotal)
+		}
+
+		present := 0
+		for _, resource := range status.Resources {
+			if resource.Present {
+				present++
+			}
+		}
+		resources := fmt.Sprintf("%d/%d", present, len(status.Resources))
+
+		problems := "-"
+		if len(status.Problems) > 0 {
+			problems = strings.Join(status.Problems, "; ")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", status.Component, health, version, pods, resources, problems)
+	}
+
+	w.Flush()
+}
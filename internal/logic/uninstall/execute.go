@@ -1,25 +1,161 @@
 package uninstall
 
-import "fmt"
+import (
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/events"
+	"austinhome/internal/logic/install"
+	"austinhome/internal/logic/lock"
+	"austinhome/internal/logic/manifest"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
 
-func Execute() error {
-	// Stop and delete Colima instance
-	stopColima()
-	deleteColima()
+// cfg holds the configuration of the running uninstallation
+var cfg = config.Default()
 
-	// Uninstall Helm if needed
-	if err := UninstallHelm(); err != nil {
-		fmt.Printf("Warning: Helm uninstall failed: %v\n", err)
+// Options controls how Execute performs the uninstallation
+type Options struct {
+	// Runner executes external commands, defaults to common.ExecRunner
+	Runner common.Runner
+	// Config selects the cluster provider and names the cluster to remove, defaults to config.Default()
+	Config *config.Config
+	// Components removes just these add-ons and keeps the cluster, empty removes everything
+	Components []string
+	// LockFile verifies the manifests of removed components, defaults to lock.DefaultFileName
+	LockFile string
+	// DryRun prints what would be removed without removing anything
+	DryRun bool
+	// Yes skips the confirmation prompt
+	Yes bool
+	// Purge also removes the whole ~/.austinhome directory, provider directories
+	// and the Homebrew cache that austinhome did not create, instead of only
+	// what the install manifest lists
+	Purge bool
+}
+
+// ErrCancelled is returned when the user declines the confirmation prompt
+var ErrCancelled = errors.New("uninstallation cancelled, nothing was removed")
+
+// Execute removes the cluster and local tooling, or only opts.Components
+func Execute(opts Options) error {
+	common.SetRunner(opts.Runner)
+
+	if opts.Config != nil {
+		cfg = opts.Config
+	}
+
+	provider, err := cluster.New(cfg)
+	if err != nil {
+		return err
+	}
+	var components []install.Component
+	if len(opts.Components) > 0 && opts.Purge {
+		return fmt.Errorf("--purge removes everything and cannot be combined with component names")
+	}
+	if len(opts.Components) > 0 {
+		cluster.Bind(provider)
+		if opts.LockFile == "" {
+			opts.LockFile = lock.DefaultFileName
+		}
+		install.UseLockFile(opts.LockFile)
+		if components, err = install.UninstallOrder(cfg, opts.Components); err != nil {
+			return err
+		}
+	} else if !provider.Managed() {
+		return fmt.Errorf("uninstall removes the whole cluster and kubeconfig, which the %s provider does not own", provider.Name())
+	}
+
+	m, err := manifest.Load()
+	if err != nil {
+		return err
+	}
+
+	items, err := inventory(provider, components, m, opts.Purge)
+	if err != nil {
+		return err
+	}
+	fmt.Println("📋 The following will be removed:")
+	printInventory(os.Stdout, items)
+
+	if opts.DryRun {
+		fmt.Println("\n📝 Dry run, nothing was removed")
+		return nil
+	}
+	if !opts.Yes {
+		if err := confirmRemoval(); err != nil {
+			return err
+		}
+	}
+
+	if len(components) > 0 {
+		return removeComponents(components)
+	}
+
+	// Delete the cluster
+	if err := runPhase("delete-cluster", provider.Delete); err != nil {
+		common.Warnf("cluster deletion failed: %v", err)
+	}
+
+	// Remove the charts downloaded by the embedded Helm SDK
+	if err := runPhase("cleanup-helm-cache", cleanupHelmCache); err != nil {
+		common.Warnf("Helm cache cleanup failed: %v", err)
 	}
 
 	// Cleanup remaining resources
-	if err := cleanupDirectories(); err != nil {
+	if err := runPhase("cleanup-directories", func() error {
+		return cleanupDirectories(removedDirectories(m, opts.Purge), removedFiles(m, opts.Purge))
+	}); err != nil {
 		return err
 	}
 
-	cleanupKubectlConfig()
-	killRemainingProcesses()
-	cleanHomebrew()
+	if err := runPhase("cleanup-kubectl-config", func() error { return cleanupKubectlConfig(provider) }); err != nil {
+		common.Warnf("kubeconfig cleanup failed: %v", err)
+	}
+	runPhase("kill-processes", func() error { killRemainingProcesses(); return nil })
+	if len(m.Formulae) > 0 {
+		runPhase("uninstall-formulae", func() error { uninstallFormulae(m.Formulae); return nil })
+	}
+	if opts.Purge {
+		runPhase("clean-homebrew", func() error { cleanHomebrew(); return nil })
+	}
+
+	// Everything the manifest listed is gone
+	if err := manifest.Remove(); err != nil {
+		common.Warnf("%v", err)
+	}
+	return nil
+}
+
+// removeComponents uninstalls only the given add-ons, already in reverse
+// dependency order, leaving the cluster and every other component in place
+func removeComponents(components []install.Component) error {
+	for _, component := range components {
+		fmt.Printf("\n▶️ Uninstalling %s\n", component.Name)
+		if err := runPhase(component.Name, component.Uninstall); err != nil {
+			return fmt.Errorf("failed to uninstall %s: %v", component.Name, err)
+		}
+		if err := install.ForgetComponents(cfg, []string{component.Name}); err != nil {
+			common.Warnf("failed to update install state: %v", err)
+		}
+	}
+	return nil
+}
+
+// runPhase runs a single uninstall phase and reports it to the event stream
+func runPhase(name string, phase func() error) error {
+	events.StepStarted(name)
+	started := time.Now()
+
+	err := phase()
+	if err != nil {
+		events.StepFinished(name, events.StatusFailed, time.Since(started), err.Error())
+		return err
+	}
 
+	events.StepFinished(name, events.StatusSucceeded, time.Since(started), "")
 	return nil
-}
\ No newline at end of file
+}
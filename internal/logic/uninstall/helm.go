@@ -7,70 +7,39 @@ import (
 	"path/filepath"
 )
 
-func UninstallHelm() error {
-	fmt.Println("⛵ Uninstalling Helm...")
+// cleanupHelmCache removes the chart repository cache used by the embedded Helm SDK
+func cleanupHelmCache() error {
+	fmt.Println("⛵ Cleaning up Helm chart cache...")
 
-	removeHelmBinary()
-	cleanupHelmDirectories()
-	removeHelmFromPath()
+	appDir, err := common.AppDir()
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("✅ Helm uninstallation completed")
+	removeDirectoryIfExists(filepath.Join(appDir, "helm"))
 	return nil
 }
 
-func removeHelmBinary() {
-	fmt.Println("🗑️ Removing Helm binary...")
-
-	helmPaths := []string{
-		"/usr/local/bin/helm",
-		"/opt/homebrew/bin/helm",
-		filepath.Join(os.Getenv("HOME"), ".local/bin/helm"),
-	}
-
-	for _, path := range helmPaths {
+// legacyHelmBinaries lists the helm binaries that versions before the embedded
+// Helm SDK installed with get_helm.sh. They were never recorded in the install
+// manifest, so only --purge removes them. Homebrew's helm is left to brew.
+func legacyHelmBinaries(homeDir string) []string {
+	var binaries []string
+	for _, path := range []string{"/usr/local/bin/helm", filepath.Join(homeDir, ".local", "bin", "helm")} {
 		if _, err := os.Stat(path); err == nil {
-			if err := os.Remove(path); err != nil {
-				fmt.Printf("Warning: failed to remove %s: %v\n", path, err)
-			} else {
-				fmt.Printf("Removed: %s\n", path)
-			}
+			binaries = append(binaries, path)
 		}
 	}
+	return binaries
 }
 
-func cleanupHelmDirectories() {
-	fmt.Println("🧹 Cleaning up Helm directories...")
-
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Printf("Warning: failed to get home directory: %v\n", err)
-		return
-	}
-
-	helmDirs := []string{
+// legacyHelmDirectories lists the configuration and cache directories of the
+// helm binary, removed together with it under --purge
+func legacyHelmDirectories(homeDir string) []string {
+	return []string{
 		filepath.Join(homeDir, ".helm"),
 		filepath.Join(homeDir, ".config", "helm"),
 		filepath.Join(homeDir, ".cache", "helm"),
 		filepath.Join(homeDir, "Library", "Caches", "helm"),
 	}
-
-	for _, dir := range helmDirs {
-		if _, err := os.Stat(dir); err == nil {
-			if err := os.RemoveAll(dir); err != nil {
-				fmt.Printf("Warning: failed to remove %s: %v\n", dir, err)
-			} else {
-				fmt.Printf("Removed directory: %s\n", dir)
-			}
-		}
-	}
 }
-
-func removeHelmFromPath() {
-	fmt.Println("🔄 Checking for Helm in system...")
-
-	if common.IsCommandAvailable("helm") {
-		fmt.Println("⚠️ Helm is still available in PATH. You may need to restart your shell or manually remove it.")
-	} else {
-		fmt.Println("✅ Helm successfully removed from system")
-	}
-}
\ No newline at end of file
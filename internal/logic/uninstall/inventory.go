@@ -0,0 +1,179 @@
+package uninstall
+
+import (
+	"austinhome/internal/logic/cluster"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/events"
+	"austinhome/internal/logic/install"
+	"austinhome/internal/logic/manifest"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// Item is something uninstall is about to remove
+type Item struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Size is the disk space freed in bytes, -1 when unknown
+	Size int64 `json:"size"`
+}
+
+// inventory lists everything a full uninstall removes, or only the components when given
+func inventory(provider cluster.Provider, components []install.Component, m *manifest.Manifest, purge bool) ([]Item, error) {
+	if len(components) > 0 {
+		var items []Item
+		for _, component := range components {
+			items = append(items, Item{Kind: "component", Name: component.Name, Size: -1})
+		}
+		return items, nil
+	}
+
+	dataDirs, clusterSize := measure(provider.DataDirs())
+	items := []Item{{Kind: "cluster", Name: fmt.Sprintf("%s %s", provider.Name(), cfg.Cluster.Name), Size: clusterSize}}
+
+	appDir, err := common.AppDir()
+	if err != nil {
+		return nil, err
+	}
+	directories := []string{filepath.Join(appDir, "helm")}
+	directories = append(directories, removedDirectories(m, purge)...)
+	for _, dir := range directories {
+		if within(dir, directories) {
+			continue
+		}
+		if size, err := dirSize(dir); err == nil {
+			// The cluster's own data is already counted by the cluster item
+			for path, dataSize := range dataDirs {
+				if within(path, []string{dir}) {
+					size -= dataSize
+				}
+			}
+			items = append(items, Item{Kind: "directory", Name: dir, Size: size})
+		}
+	}
+	for _, file := range removedFiles(m, purge) {
+		if within(file, directories) {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			items = append(items, Item{Kind: "file", Name: file, Size: info.Size()})
+		}
+	}
+
+	files, contextName := kubeconfigFiles(provider)
+	if contextName != "" {
+		for _, file := range files {
+			if definesContext(file, contextName) {
+				items = append(items, Item{Kind: "kube-context", Name: fmt.Sprintf("%s in %s", contextName, file), Size: -1})
+			}
+		}
+	}
+
+	for _, formula := range m.Formulae {
+		items = append(items, Item{Kind: "formula", Name: formula, Size: -1})
+	}
+	if purge && common.IsCommandAvailable("brew") {
+		items = append(items, Item{Kind: "homebrew-cache", Name: "brew cleanup", Size: -1})
+	}
+	return items, nil
+}
+
+// printInventory renders items as a table and reports them to the event stream
+func printInventory(out io.Writer, items []Item) {
+	events.Emit(events.Event{Type: events.TypeInventory, Data: items})
+
+	var total int64
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tSIZE")
+	for _, item := range items {
+		size := "-"
+		if item.Size >= 0 {
+			size = humanSize(item.Size)
+			total += item.Size
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", item.Kind, item.Name, size)
+	}
+	if total > 0 {
+		fmt.Fprintf(w, "total\t\t%s\n", humanSize(total))
+	}
+	w.Flush()
+}
+
+// dirSize sums the size of every regular file below dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// measure sums the sizes of the existing dirs, -1 when none can be measured,
+// e.g. a data dir only root may read
+func measure(dirs []string) (map[string]int64, int64) {
+	sizes := map[string]int64{}
+	var total int64 = -1
+	for _, dir := range dirs {
+		size, err := dirSize(dir)
+		if err != nil {
+			continue
+		}
+		sizes[dir] = size
+		total = max(total, 0) + size
+	}
+	return sizes, total
+}
+
+// within reports whether path lies inside one of directories, so its size is
+// already counted there
+func within(path string, directories []string) bool {
+	for _, dir := range directories {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+func humanSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value, exp := float64(bytes)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exp])
+}
+
+// confirmRemoval asks before anything is removed. Without a terminal there is
+// nobody to ask, so --yes is required.
+func confirmRemoval() error {
+	if !common.StdinIsTerminal() {
+		return fmt.Errorf("refusing to uninstall without confirmation: stdin is not a terminal, pass --yes")
+	}
+
+	fmt.Println()
+	if !common.Confirm("Remove everything listed above?") {
+		return ErrCancelled
+	}
+	return nil
+}
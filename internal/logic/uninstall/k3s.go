@@ -2,75 +2,108 @@ package uninstall
 
 import (
 	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/manifest"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 )
 
-const colimaInstanceName = "k3s-homeserver"
+func cleanupDirectories(directories, files []string) error {
+	fmt.Println("🧽 Cleaning up remaining files...")
 
-func stopColima() {
-	fmt.Println("⏹️ Stopping Colima instance...")
-	if err := common.RunCommand("colima", "stop", colimaInstanceName); err != nil {
-		fmt.Printf("Warning: failed to stop Colima: %v\n", err)
+	for _, dir := range directories {
+		removeDirectoryIfExists(dir)
 	}
+	for _, file := range files {
+		removeFileIfExists(file)
+	}
+
+	return nil
 }
 
-func deleteColima() {
-	fmt.Println("💥 Deleting Colima instance...")
-	if err := common.RunCommand("colima", "delete", colimaInstanceName, "--force"); err != nil {
-		fmt.Printf("Warning: failed to delete Colima: %v\n", err)
+// removedDirectories lists the directories cleanupDirectories removes: those
+// the install manifest says austinhome created, and with purge the whole app
+// directory and every provider directory whether or not it existed before.
+// ~/.kube is shared with other clusters, see cleanupKubectlConfig.
+func removedDirectories(m *manifest.Manifest, purge bool) []string {
+	directories := slices.Clone(m.Directories)
+	if !purge {
+		return directories
 	}
-}
 
-func cleanupDirectories() error {
-	fmt.Println("🧽 Cleaning up remaining files...")
+	if appDir, err := common.AppDir(); err != nil {
+		common.Warnf("failed to get app directory: %v", err)
+	} else if !slices.Contains(directories, appDir) {
+		directories = append(directories, appDir)
+	}
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return fmt.Errorf("failed to get home directory: %v", err)
+		common.Warnf("failed to get home directory: %v", err)
+		return directories
 	}
 
-	directoriesToRemove := []string{
-		filepath.Join(homeDir, ".kube"),
-		filepath.Join(homeDir, ".colima"),
+	if cfg.Cluster.Provider == config.ProviderColima {
+		if dir := filepath.Join(homeDir, ".colima"); !slices.Contains(directories, dir) {
+			directories = append(directories, dir)
+		}
 	}
+	return append(directories, legacyHelmDirectories(homeDir)...)
+}
 
-	for _, dir := range directoriesToRemove {
-		removeDirectoryIfExists(dir)
+// removedFiles lists the files cleanupDirectories removes: those the install
+// manifest records, and with purge the helm binary of older versions
+func removedFiles(m *manifest.Manifest, purge bool) []string {
+	files := slices.Clone(m.Files)
+	if !purge {
+		return files
 	}
 
-	return nil
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		common.Warnf("failed to get home directory: %v", err)
+		return files
+	}
+	return append(files, legacyHelmBinaries(homeDir)...)
 }
 
 func removeDirectoryIfExists(dir string) {
 	if _, err := os.Stat(dir); err == nil {
 		fmt.Printf("Removing directory: %s\n", dir)
 		if err := os.RemoveAll(dir); err != nil {
-			fmt.Printf("Warning: failed to remove %s: %v\n", dir, err)
+			common.Warnf("failed to remove %s: %v", dir, err)
+		}
+	}
+}
+
+func removeFileIfExists(file string) {
+	if _, err := os.Stat(file); err == nil {
+		fmt.Printf("Removing file: %s\n", file)
+		if err := os.Remove(file); err != nil {
+			common.Warnf("failed to remove %s: %v", file, err)
 		}
 	}
 }
 
 func killRemainingProcesses() {
 	fmt.Println("🔄 Cleaning up remaining processes...")
-	// Colima manages its own processes, so no manual cleanup needed
+	// Every provider manages its own processes, so no manual cleanup needed
 	fmt.Println("✅ No additional processes to clean up")
 }
 
+// uninstallFormulae removes the Homebrew formulae austinhome installed
+func uninstallFormulae(formulae []string) {
+	fmt.Println("🍺 Uninstalling Homebrew formulae installed by austinhome...")
+	for _, formula := range formulae {
+		if err := common.RunCommand("brew", "uninstall", formula); err != nil {
+			common.Warnf("failed to uninstall %s: %v", formula, err)
+		}
+	}
+}
+
 func cleanHomebrew() {
 	fmt.Println("🧹 Cleaning Homebrew cache...")
 	common.RunCommand("brew", "cleanup")
 }
-
-func cleanupKubectlConfig() {
-	fmt.Println("🔧 Cleaning kubectl configuration...")
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Printf("Warning: failed to get home directory: %v\n", err)
-		return
-	}
-
-	kubeDir := filepath.Join(homeDir, ".kube")
-	common.RunCommand("rm", "-rf", kubeDir)
-}
\ No newline at end of file
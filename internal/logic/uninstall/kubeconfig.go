@@ -0,0 +1,118 @@
+package uninstall
+
+import (
+	"austinhome/internal/logic/cluster"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// cleanupKubectlConfig removes the provider's context from every kubeconfig
+// file that has it, along with the cluster and user only that context used.
+// Each modified file is backed up first and unrelated entries stay untouched.
+func cleanupKubectlConfig(provider cluster.Provider) error {
+	fmt.Println("🔧 Cleaning kubectl configuration...")
+
+	files, contextName := kubeconfigFiles(provider)
+	if contextName == "" {
+		fmt.Println("ℹ️ The provider has no dedicated kubeconfig context, nothing to clean up")
+		return nil
+	}
+
+	for _, file := range files {
+		if err := removeKubeconfigContext(file, contextName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kubeconfigFiles returns the kubeconfig files the provider's context may be
+// defined in, following $KUBECONFIG like kubectl, and the context name
+func kubeconfigFiles(provider cluster.Provider) ([]string, string) {
+	path, contextName := provider.Kubeconfig()
+	if path != "" {
+		return []string{path}, contextName
+	}
+	return clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence(), contextName
+}
+
+// definesContext reports whether the kubeconfig file defines contextName
+func definesContext(file, contextName string) bool {
+	kubeconfig, err := clientcmd.LoadFromFile(file)
+	if err != nil {
+		return false
+	}
+	_, ok := kubeconfig.Contexts[contextName]
+	return ok
+}
+
+// removeKubeconfigContext removes contextName from file if it is defined there
+func removeKubeconfigContext(file, contextName string) error {
+	original, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read kubeconfig %s: %v", file, err)
+	}
+
+	kubeconfig, err := clientcmd.Load(original)
+	if err != nil {
+		return fmt.Errorf("failed to parse kubeconfig %s: %v", file, err)
+	}
+
+	removed, ok := kubeconfig.Contexts[contextName]
+	if !ok {
+		return nil
+	}
+	delete(kubeconfig.Contexts, contextName)
+	if kubeconfig.CurrentContext == contextName {
+		kubeconfig.CurrentContext = ""
+	}
+
+	entries := []string{"context " + contextName}
+	if !referenced(kubeconfig, func(c *clientcmdapi.Context) bool { return c.Cluster == removed.Cluster }) {
+		if _, ok := kubeconfig.Clusters[removed.Cluster]; ok {
+			delete(kubeconfig.Clusters, removed.Cluster)
+			entries = append(entries, "cluster "+removed.Cluster)
+		}
+	}
+	if !referenced(kubeconfig, func(c *clientcmdapi.Context) bool { return c.AuthInfo == removed.AuthInfo }) {
+		if _, ok := kubeconfig.AuthInfos[removed.AuthInfo]; ok {
+			delete(kubeconfig.AuthInfos, removed.AuthInfo)
+			entries = append(entries, "user "+removed.AuthInfo)
+		}
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return fmt.Errorf("failed to stat kubeconfig %s: %v", file, err)
+	}
+	backup := fmt.Sprintf("%s.%s.bak", file, time.Now().Format("20060102-150405"))
+	if err := os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to back up kubeconfig %s: %v", file, err)
+	}
+
+	if err := clientcmd.WriteToFile(*kubeconfig, file); err != nil {
+		return fmt.Errorf("failed to write kubeconfig %s: %v", file, err)
+	}
+
+	fmt.Printf("✅ Removed %s from %s (backup: %s)\n", strings.Join(entries, ", "), file, backup)
+	return nil
+}
+
+// referenced reports whether any remaining context matches
+func referenced(kubeconfig *clientcmdapi.Config, matches func(*clientcmdapi.Context) bool) bool {
+	for _, context := range kubeconfig.Contexts {
+		if matches(context) {
+			return true
+		}
+	}
+	return false
+}
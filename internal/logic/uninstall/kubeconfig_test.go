@@ -0,0 +1,175 @@
+package uninstall
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// kubeconfig builds a kubeconfig whose contexts map to cluster and user names
+func kubeconfig(current string, contexts map[string][2]string) *clientcmdapi.Config {
+	config := clientcmdapi.NewConfig()
+	config.CurrentContext = current
+	for name, target := range contexts {
+		cluster, user := target[0], target[1]
+		config.Contexts[name] = &clientcmdapi.Context{Cluster: cluster, AuthInfo: user}
+		config.Clusters[cluster] = &clientcmdapi.Cluster{Server: "https://" + cluster + ":6443"}
+		config.AuthInfos[user] = &clientcmdapi.AuthInfo{Token: user + "-token"}
+	}
+	return config
+}
+
+func keys[V any](m map[string]V) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestRemoveKubeconfigContext(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *clientcmdapi.Config
+		context string
+
+		contexts []string
+		clusters []string
+		users    []string
+		current  string
+		// unchanged expects the file to be left alone without a backup
+		unchanged bool
+	}{
+		{
+			name: "dedicated entries",
+			config: kubeconfig("work", map[string][2]string{
+				"k3d-austinhome": {"k3d-austinhome", "admin@k3d-austinhome"},
+				"work":           {"work", "work-user"},
+			}),
+			context:  "k3d-austinhome",
+			contexts: []string{"work"},
+			clusters: []string{"work"},
+			users:    []string{"work-user"},
+			current:  "work",
+		},
+		{
+			name: "shared cluster and user",
+			config: kubeconfig("work", map[string][2]string{
+				"austinhome": {"shared", "shared-user"},
+				"work":       {"shared", "shared-user"},
+			}),
+			context:  "austinhome",
+			contexts: []string{"work"},
+			clusters: []string{"shared"},
+			users:    []string{"shared-user"},
+			current:  "work",
+		},
+		{
+			name: "shared user only",
+			config: kubeconfig("work", map[string][2]string{
+				"austinhome": {"austinhome", "shared-user"},
+				"work":       {"work", "shared-user"},
+			}),
+			context:  "austinhome",
+			contexts: []string{"work"},
+			clusters: []string{"work"},
+			users:    []string{"shared-user"},
+			current:  "work",
+		},
+		{
+			name: "current context",
+			config: kubeconfig("colima", map[string][2]string{
+				"colima": {"colima", "colima"},
+				"work":   {"work", "work-user"},
+			}),
+			context:  "colima",
+			contexts: []string{"work"},
+			clusters: []string{"work"},
+			users:    []string{"work-user"},
+			current:  "",
+		},
+		{
+			name: "context not defined",
+			config: kubeconfig("work", map[string][2]string{
+				"work": {"work", "work-user"},
+			}),
+			context:   "k3d-austinhome",
+			unchanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config")
+			original, err := clientcmd.Write(*tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, original, 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := removeKubeconfigContext(file, tt.context); err != nil {
+				t.Fatalf("removeKubeconfigContext: %v", err)
+			}
+
+			backups, err := filepath.Glob(file + ".*.bak")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.unchanged {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(data, original) || len(backups) != 0 {
+					t.Fatalf("file changed or backed up (%q) although %s is not defined", backups, tt.context)
+				}
+				return
+			}
+
+			if len(backups) != 1 {
+				t.Fatalf("got backups %q, want one", backups)
+			}
+			backup, err := os.ReadFile(backups[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(backup, original) {
+				t.Fatalf("backup differs from the original kubeconfig:\n%s", backup)
+			}
+			if info, err := os.Stat(backups[0]); err != nil || info.Mode().Perm() != 0600 {
+				t.Fatalf("backup mode is %v (%v), want 0600", info.Mode().Perm(), err)
+			}
+
+			got, err := clientcmd.LoadFromFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := keys(got.Contexts); !slices.Equal(names, tt.contexts) {
+				t.Errorf("contexts are %q, want %q", names, tt.contexts)
+			}
+			if names := keys(got.Clusters); !slices.Equal(names, tt.clusters) {
+				t.Errorf("clusters are %q, want %q", names, tt.clusters)
+			}
+			if names := keys(got.AuthInfos); !slices.Equal(names, tt.users) {
+				t.Errorf("users are %q, want %q", names, tt.users)
+			}
+			if got.CurrentContext != tt.current {
+				t.Errorf("current context is %q, want %q", got.CurrentContext, tt.current)
+			}
+		})
+	}
+}
+
+func TestRemoveKubeconfigContextMissingFile(t *testing.T) {
+	if err := removeKubeconfigContext(filepath.Join(t.TempDir(), "config"), "colima"); err != nil {
+		t.Fatalf("got %v for a missing kubeconfig, want nil", err)
+	}
+}
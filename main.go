@@ -1,10 +1,21 @@
 package main
 
 import (
+	"austinhome/internal/logic/bundle"
+	"austinhome/internal/logic/common"
+	"austinhome/internal/logic/config"
+	"austinhome/internal/logic/doctor"
+	"austinhome/internal/logic/events"
 	"austinhome/internal/logic/install"
+	"austinhome/internal/logic/lock"
+	"austinhome/internal/logic/status"
 	"austinhome/internal/logic/uninstall"
+	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 )
 
 const appName = "austinhome"
@@ -18,36 +29,351 @@ func main() {
 	command := os.Args[1]
 	switch command {
 	case "install":
-		executeInstall()
+		executeInstall(os.Args[2:])
 	case "uninstall":
-		executeUninstall()
+		executeUninstall(os.Args[2:])
+	case "status":
+		executeStatus(os.Args[2:])
+	case "doctor":
+		executeDoctor(os.Args[2:])
+	case "bundle":
+		executeBundle(os.Args[2:])
+	case "lock":
+		executeLock(os.Args[2:])
 	default:
 		handleUnknownCommand(command)
 	}
 }
 
-func executeInstall() {
-	fmt.Println("🚀 Starting installation...")
+func executeInstall(args []string) {
+	flags := flag.NewFlagSet("install", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "Print every command, chart and manifest without applying anything")
+	configPath := flags.String("config", "", "Path to the config file (default ./"+config.DefaultFileName+" if present)")
+	envLabel := flags.String("env-label", "", "Environment label for the cluster node (env: "+install.EnvLabelVar+")")
+	patFile := flags.String("gitlab-pat-file", "", "Read the GitLab PAT from this file")
+	patEnv := flags.String("gitlab-pat-env", "", "Read the GitLab PAT from this environment variable (default "+install.GitLabPATVar+")")
+	kubeContext := flags.String("kube-context", "", "Install the components onto this context of an existing cluster instead of creating one")
+	kubeconfig := flags.String("kubeconfig", "", "Install the components onto an existing cluster from this kubeconfig file")
+	bundlePath := flags.String("bundle", "", "Install every manifest and chart from a bundle created with 'bundle create'")
+	lockPath := flags.String("lock", lock.DefaultFileName, "Lock file with the expected SHA-256 of every manifest and chart")
+	resume := flags.Bool("resume", false, "Continue a failed install from its last checkpoint")
+	recreate := flags.Bool("recreate-cluster", false, "Replace the cluster with a fresh one even if it is already running")
+	noRollback := flags.Bool("no-rollback", false, "Keep the components of a failed install for debugging instead of removing them")
+	output := flags.String("output", "text", "Output format: text or json (JSON events on stdout, progress on stderr)")
+	only := flags.String("only", "", "Comma separated components to install, dependencies are added automatically")
+	skip := flags.String("skip", "", "Comma separated components to leave out")
+	flags.Parse(args)
 
-	if err := install.Execute(); err != nil {
+	started := time.Now()
+	setupOutput(*output)
+	cfg := loadConfig("install", started, *configPath)
+
+	if *dryRun {
+		fmt.Println("📝 Planning installation (dry run, nothing will be changed)...")
+	} else {
+		fmt.Println("🚀 Starting installation...")
+	}
+
+	opts := install.Options{
+		Runner: common.ExecRunner{},
+		Config: cfg,
+		DryRun: *dryRun,
+
+		EnvLabel:      *envLabel,
+		GitLabPATFile: *patFile,
+		GitLabPATEnv:  *patEnv,
+
+		KubeContext: *kubeContext,
+		Kubeconfig:  *kubeconfig,
+
+		Bundle:   *bundlePath,
+		LockFile: *lockPath,
+
+		Resume:          *resume,
+		RecreateCluster: *recreate,
+		NoRollback:      *noRollback,
+
+		Only: splitList(*only),
+		Skip: splitList(*skip),
+	}
+	err := install.Execute(opts)
+	printStepSummary(started)
+	if err != nil {
 		fmt.Printf("Error during installation: %v\n", err)
-		os.Exit(1)
+		fail("install", started, err)
 	}
 
+	events.Summary("install", started, nil, nil)
+	if *dryRun {
+		fmt.Println("✅ Dry run completed, no changes were made")
+		return
+	}
 	fmt.Println("✅ Installation completed successfully!")
 }
 
-func executeUninstall() {
+func executeUninstall(args []string) {
+	flags := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the config file (default ./"+config.DefaultFileName+" if present)")
+	output := flags.String("output", "text", "Output format: text or json (JSON events on stdout, progress on stderr)")
+	dryRun := flags.Bool("dry-run", false, "List everything that would be removed without removing it")
+	yes := flags.Bool("yes", false, "Remove without asking for confirmation")
+	purge := flags.Bool("purge", false, "Also remove ~/.austinhome, provider directories, the legacy helm binary and the Homebrew cache austinhome did not create")
+	lockPath := flags.String("lock", lock.DefaultFileName, "Lock file verifying the manifests of the removed components")
+	flags.Parse(args)
+	components := componentArgs(flags)
+
+	started := time.Now()
+	setupOutput(*output)
+	cfg := loadConfig("uninstall", started, *configPath)
+
 	fmt.Println("🗑️ Starting uninstallation...")
 
-	if err := uninstall.Execute(); err != nil {
+	opts := uninstall.Options{
+		Runner:     common.ExecRunner{},
+		Config:     cfg,
+		Components: components,
+		LockFile:   *lockPath,
+		DryRun:     *dryRun,
+		Yes:        *yes,
+		Purge:      *purge,
+	}
+	err := uninstall.Execute(opts)
+	printStepSummary(started)
+	if err != nil {
 		fmt.Printf("Error during uninstallation: %v\n", err)
-		os.Exit(1)
+		fail("uninstall", started, err)
 	}
 
+	events.Summary("uninstall", started, nil, nil)
+	if *dryRun {
+		fmt.Println("✅ Dry run completed, no changes were made")
+		return
+	}
 	fmt.Println("✅ Uninstallation completed successfully!")
 }
 
+func executeStatus(args []string) {
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the config file (default ./"+config.DefaultFileName+" if present)")
+	output := flags.String("output", "text", "Output format: text or json (JSON events on stdout, table on stderr)")
+	flags.Parse(args)
+
+	started := time.Now()
+	setupOutput(*output)
+	cfg := loadConfig("status", started, *configPath)
+
+	opts := status.Options{
+		Runner: common.ExecRunner{},
+		Config: cfg,
+	}
+	statuses, err := status.Execute(opts)
+	if err != nil {
+		fmt.Printf("\n❌ Cluster is degraded: %v\n", err)
+		events.Summary("status", started, err, statuses)
+		os.Exit(1)
+	}
+
+	events.Summary("status", started, nil, statuses)
+	fmt.Println("\n✅ All components are healthy")
+}
+
+func executeDoctor(args []string) {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the config file (default ./"+config.DefaultFileName+" if present)")
+	offline := flags.Bool("offline", false, "Skip resolving chart and manifest hosts, for installs from a bundle")
+	output := flags.String("output", "text", "Output format: text or json (JSON events on stdout, table on stderr)")
+	flags.Parse(args)
+
+	started := time.Now()
+	setupOutput(*output)
+	cfg := loadConfig("doctor", started, *configPath)
+
+	opts := doctor.Options{
+		Runner:  common.ExecRunner{},
+		Config:  cfg,
+		Offline: *offline,
+	}
+	results, err := doctor.Execute(opts)
+	if err != nil {
+		fmt.Printf("\n❌ The host is not ready: %v\n", err)
+		events.Summary("doctor", started, err, results)
+		os.Exit(1)
+	}
+
+	events.Summary("doctor", started, nil, results)
+	fmt.Println("\n✅ The host is ready for install")
+}
+
+func executeBundle(args []string) {
+	if len(args) == 0 || args[0] != "create" {
+		fmt.Println("Usage: austinhome bundle create [--config <path>] [--lock <path>] [--out <file>]")
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("bundle create", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the config file (default ./"+config.DefaultFileName+" if present)")
+	lockPath := flags.String("lock", lock.DefaultFileName, "Lock file the downloaded artifacts are verified against")
+	out := flags.String("out", bundle.DefaultFileName, "Path of the bundle archive to write")
+	output := flags.String("output", "text", "Output format: text or json (JSON events on stdout, progress on stderr)")
+	flags.Parse(args[1:])
+
+	started := time.Now()
+	setupOutput(*output)
+	cfg := loadConfig("bundle", started, *configPath)
+
+	l, err := lock.Load(*lockPath)
+	if err == nil {
+		err = l.Covers(cfg)
+	}
+	if err != nil {
+		fmt.Printf("Error loading lock file: %v\n", err)
+		fail("bundle", started, err)
+	}
+
+	fmt.Println("📦 Creating offline bundle...")
+	index, err := bundle.Create(cfg, l, *out)
+	if err != nil {
+		fmt.Printf("Error creating bundle: %v\n", err)
+		fail("bundle", started, err)
+	}
+
+	events.Summary("bundle", started, nil, index)
+	fmt.Printf("✅ Bundle written to %s (%d files, %d charts)\n", *out, len(index.Files), len(index.Charts))
+}
+
+func executeLock(args []string) {
+	if len(args) == 0 || args[0] != "update" {
+		fmt.Println("Usage: austinhome lock update [--config <path>] [--lock <path>]")
+		os.Exit(1)
+	}
+
+	flags := flag.NewFlagSet("lock update", flag.ExitOnError)
+	configPath := flags.String("config", "", "Path to the config file (default ./"+config.DefaultFileName+" if present)")
+	lockPath := flags.String("lock", lock.DefaultFileName, "Lock file to write")
+	output := flags.String("output", "text", "Output format: text or json (JSON events on stdout, progress on stderr)")
+	flags.Parse(args[1:])
+
+	started := time.Now()
+	setupOutput(*output)
+	cfg := loadConfig("lock", started, *configPath)
+
+	fmt.Println("🔏 Refreshing artifact digests...")
+	next, err := lock.Update(cfg)
+	if err != nil {
+		fmt.Printf("Error updating lock file: %v\n", err)
+		fail("lock", started, err)
+	}
+
+	var changes []string
+	if previous, err := lock.Load(*lockPath); err == nil {
+		changes = previous.Diff(next)
+	} else {
+		changes = (&lock.Lock{}).Diff(next)
+	}
+
+	if err := next.Save(*lockPath); err != nil {
+		fmt.Printf("Error updating lock file: %v\n", err)
+		fail("lock", started, err)
+	}
+
+	for _, change := range changes {
+		fmt.Printf("  %s\n", change)
+	}
+	events.Summary("lock", started, nil, changes)
+	fmt.Printf("✅ %s updated (%d change(s))\n", *lockPath, len(changes))
+}
+
+// setupOutput switches to a JSON event stream on stdout when requested. Human
+// readable progress and child process output are moved to stderr so that
+// stdout only carries one JSON object per line.
+func setupOutput(format string) {
+	switch format {
+	case "text":
+	case "json":
+		events.Enable(os.Stdout)
+		os.Stdout = os.Stderr
+	default:
+		fmt.Printf("Unknown output format: %s (expected text or json)\n", format)
+		os.Exit(1)
+	}
+}
+
+// printStepSummary prints every recorded step with its status, duration and message
+func printStepSummary(started time.Time) {
+	steps := events.Steps()
+	if len(steps) == 0 {
+		return
+	}
+
+	icons := map[string]string{
+		events.StatusSucceeded: "✅ ok",
+		events.StatusWarning:   "⚠️ warn",
+		events.StatusFailed:    "❌ failed",
+		events.StatusSkipped:   "⏭️ skipped",
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tDURATION\tMESSAGE")
+	for _, step := range steps {
+		status, ok := icons[step.Status]
+		if !ok {
+			status = step.Status
+		}
+
+		message := "-"
+		if step.Message != "" {
+			message = strings.SplitN(step.Message, "\n", 2)[0]
+		}
+
+		duration := (time.Duration(step.DurationMS) * time.Millisecond).Round(100 * time.Millisecond)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", step.Step, status, duration, message)
+	}
+	fmt.Fprintf(w, "total\t\t%s\t\n", time.Since(started).Round(time.Second))
+	w.Flush()
+	fmt.Println()
+}
+
+// splitList parses a comma separated flag value
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// componentArgs returns the positional arguments of flags. The flag package
+// stops parsing at the first component name, so a flag after it would be
+// taken for a component.
+func componentArgs(flags *flag.FlagSet) []string {
+	for _, arg := range flags.Args() {
+		if strings.HasPrefix(arg, "-") {
+			fmt.Fprintf(os.Stderr, "flag %s must come before the component names: %s %s [flags] [component...]\n", arg, appName, flags.Name())
+			os.Exit(2)
+		}
+	}
+	return flags.Args()
+}
+
+// fail reports err to the event stream and exits
+func fail(command string, started time.Time, err error) {
+	events.Error(err)
+	events.Summary(command, started, err, nil)
+	os.Exit(1)
+}
+
+func loadConfig(command string, started time.Time, path string) *config.Config {
+	cfg, err := config.Load(path)
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		fail(command, started, err)
+	}
+	return cfg
+}
+
 func handleUnknownCommand(command string) {
 	fmt.Printf("Unknown command: %s\n", command)
 	showUsage()
@@ -58,8 +384,50 @@ func showUsage() {
 	fmt.Printf(`Usage: %s <command>
 
 Commands:
-  install    Install K3s on Mac via Multipass VM
-  uninstall  Uninstall K3s and clean up all files
+  install    Install a Kubernetes cluster (cluster.provider: colima, k3s, k3d, kind, existing)
+             --dry-run        Print the installation plan without changing anything
+             --config <path>  Config file (default ./austinhome.yaml if present)
+             --env-label <label>       Node environment label of managed clusters (or AUSTINHOME_ENV_LABEL)
+             --gitlab-pat-file <path>  Read the GitLab PAT from a file
+             --gitlab-pat-env <name>   Read the GitLab PAT from an environment variable
+                                       (default AUSTINHOME_GITLAB_PAT)
+             --kube-context <name>     Install the components onto an existing cluster
+             --kubeconfig <path>       (no cluster is created, stopped or deleted)
+             --bundle <file>  Install manifests and charts from an offline bundle
+             --lock <path>    Lock file with artifact digests (default austinhome.lock)
+             --resume         Continue a failed install from its checkpoint in ~/.austinhome
+             --recreate-cluster  Replace a running cluster instead of reusing it
+             --no-rollback    Keep the components of a failed install for debugging
+             --output json    Emit JSON events on stdout (progress moves to stderr)
+             --only <a,b>     Install only these components (plus their dependencies)
+             --skip <a,b>     Leave these components out
+                              Components: %s
+  uninstall [flags] [component...]
+             Delete the cluster and everything recorded in ~/.austinhome/manifest.json,
+             or remove only the given components (dependents first) and keep the cluster
+             --dry-run        List everything that would be removed and exit
+             --yes            Skip the confirmation prompt (required without a terminal)
+             --purge          Also remove ~/.austinhome, provider directories, the helm
+                              binary of older versions and the Homebrew cache even if
+                              austinhome did not create them
+             --lock <path>    Lock file verifying removed manifests (default austinhome.lock)
+             --config <path>  Config file (default ./austinhome.yaml if present)
+             --output json    Emit JSON events on stdout (progress moves to stderr)
+  status     Report the health of every managed component (exit code 1 if degraded)
+             --config <path>  Config file (default ./austinhome.yaml if present)
+             --output json    Emit JSON events on stdout (table moves to stderr)
+  doctor     Check the host before installing (binaries, sizing, network, DNS, existing cluster)
+             and print pass/warn/fail with hints (exit code 1 if a check fails).
+             install runs the same checks as its prerequisites step
+             --offline        Skip resolving chart and manifest hosts (installs from a bundle)
+             --config <path>  Config file (default ./austinhome.yaml if present)
+             --output json    Emit JSON events on stdout (table moves to stderr)
+  bundle create  Download every manifest and chart for the configured versions into one archive
+             --config <path>  Config file (default ./austinhome.yaml if present)
+             --out <file>     Archive to write (default austinhome-bundle.tar.gz)
+  lock update    Download every manifest and chart and record its SHA-256 in the lock file
+             --config <path>  Config file (default ./austinhome.yaml if present)
+             --lock <path>    Lock file to write (default austinhome.lock)
 
-`, appName)
+`, appName, strings.Join(install.ComponentNames(), ", "))
 }